@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// AntitheticResult holds the outcome of a paired antithetic experiment.
+type AntitheticResult struct {
+	Plain      []float64 // estimates from the original substreams
+	Antithetic []float64 // estimates from the antithetic substreams
+	Mean       float64   // mean of the pair averages
+	Variance   float64   // sample variance of the pair averages
+	StdError   float64   // standard error of Mean
+
+	// VarianceReduction is the ratio of the variance of the mean of 2n
+	// independent replications, estimated from all the individual
+	// estimates, to the variance of Mean. Values above 1 mean that the
+	// antithetic pairs did better than independent sampling with the
+	// same number of runs.
+	VarianceReduction float64
+}
+
+// AntitheticPair runs n >= 2 pairs of replications of rep, the function
+// computing one estimate from the given streams. For pair r, every stream
+// is reset to the start of its current substream and rep(r) is run
+// normally, then every stream is reset again and switched to antithetic
+// mode, so that rep(r) sees 1-U wherever it saw U, and run a second time.
+// The streams are then moved to their next substream, for the next pair.
+//
+// The streams are left at the beginning of the substream following the
+// last pair, with antithetic mode off.
+func AntitheticPair(streams []*RngStream, n int, rep func(r int) float64) *AntitheticResult {
+	if n < 2 {
+		panic(paramError("antithetic pair: need n >= 2"))
+	}
+	res := &AntitheticResult{Plain: make([]float64, n), Antithetic: make([]float64, n)}
+	pairs := make([]float64, n)
+	for r := 0; r < n; r++ {
+		for _, g := range streams {
+			g.ResetStartSubstream()
+			g.SetAntithetic(false)
+		}
+		res.Plain[r] = rep(r)
+		for _, g := range streams {
+			g.ResetStartSubstream()
+			g.SetAntithetic(true)
+		}
+		res.Antithetic[r] = rep(r)
+		for _, g := range streams {
+			g.SetAntithetic(false)
+			g.ResetNextSubstream()
+		}
+		pairs[r] = (res.Plain[r] + res.Antithetic[r]) / 2
+	}
+	res.Mean, res.Variance = meanVariance(pairs)
+	res.StdError = math.Sqrt(res.Variance / float64(n))
+	_, v := meanVariance(append(append([]float64(nil), res.Plain...), res.Antithetic...))
+	res.VarianceReduction = v / 2 / res.Variance
+	return res
+}
+
+// meanVariance returns the mean and the unbiased sample variance of x,
+// computed with Welford's updates.
+func meanVariance(x []float64) (mean, variance float64) {
+	var m2 float64
+	for i, v := range x {
+		d := v - mean
+		mean += d / float64(i+1)
+		m2 += d * (v - mean)
+	}
+	if len(x) > 1 {
+		variance = m2 / float64(len(x)-1)
+	}
+	return mean, variance
+}
@@ -0,0 +1,47 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAntitheticPair(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g1 := New("a")
+	g2 := New("b")
+	start := *g1
+
+	// the antithetic run sees 1-U on every stream
+	res := AntitheticPair([]*RngStream{g1, g2}, 10, func(int) float64 {
+		return g1.RandU01() + 2*g2.RandU01()
+	})
+	for r := range res.Plain {
+		if s := res.Plain[r] + res.Antithetic[r]; math.Abs(s-3) > 1e-9 {
+			t.Errorf("pair %d: estimates sum to %v, wanted 3", r, s)
+		}
+	}
+	if res.Variance > 1e-18 || math.Abs(res.Mean-1.5) > 1e-12 {
+		t.Errorf("linear response: pair mean %v, variance %v", res.Mean, res.Variance)
+	}
+
+	// streams end at the next substream, in normal mode
+	start.ResetStartStream()
+	for i := 0; i < 10; i++ {
+		start.ResetNextSubstream()
+	}
+	if g1.RandU01() != start.RandU01() {
+		t.Errorf("stream not left at the start of substream 10 in normal mode")
+	}
+
+	// a monotone response: E[exp(U)] = e - 1
+	res = AntitheticPair([]*RngStream{g1}, 1000, func(int) float64 {
+		return math.Exp(g1.RandU01())
+	})
+	if math.Abs(res.Mean-(math.E-1)) > 4*res.StdError {
+		t.Errorf("mean %v, wanted %v within %v", res.Mean, math.E-1, 4*res.StdError)
+	}
+	// the exact ratio is Var(e^U)/(2 Var((e^U + e^(1-U))/2)), about 30.6
+	if res.VarianceReduction < 20 || res.VarianceReduction > 45 {
+		t.Errorf("variance reduction %v, expected about 30", res.VarianceReduction)
+	}
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	arrivalsThinning = iota
+	arrivalsConstant
+	arrivalsLinear
+)
+
+// ArrivalProcess generates the successive event times of a
+// non-homogeneous Poisson process with rate function lambda(t), driven by
+// a stream. Bounded rate functions are handled by thinning; piecewise
+// constant and piecewise linear rates by inversion of the cumulative
+// intensity, which uses exactly one uniform per event.
+//
+// ResetStartSubstream and ResetNextSubstream restart the process at its
+// initial time together with its stream, so that each replication
+// regenerates identical arrivals from its substream.
+type ArrivalProcess struct {
+	g    *RngStream
+	kind int
+	t    float64 // time of the last event
+	s    float64 // cumulative intensity of the last event (inversion)
+
+	rate    func(t float64) float64
+	rateMax float64
+
+	times []float64 // breakpoints
+	rates []float64 // rates per interval (constant) or at breakpoints (linear)
+	cum   []float64 // cumulative intensity at the breakpoints
+	cycle bool
+}
+
+// NewThinningArrivals returns the process with rate function rate, which
+// must satisfy 0 <= rate(t) <= rateMax for all t >= 0, starting at time 0.
+// Candidates are generated at rate rateMax and accepted with probability
+// rate(t)/rateMax, at a cost of two calls to RandU01 per candidate.
+// Values of rate above rateMax are treated as rateMax.
+func NewThinningArrivals(g *RngStream, rate func(t float64) float64, rateMax float64) (*ArrivalProcess, error) {
+	if rate == nil || !(rateMax > 0) || math.IsInf(rateMax, 1) {
+		return nil, paramError("arrivals: need a rate function and finite rateMax > 0")
+	}
+	return &ArrivalProcess{g: g, kind: arrivalsThinning, rate: rate, rateMax: rateMax}, nil
+}
+
+// NewPiecewiseConstantArrivals returns the process whose rate is rates[i]
+// on [times[i], times[i+1]), starting at times[0]. The breakpoints must be
+// increasing, len(times) = len(rates)+1, and the rates non-negative with
+// at least one positive. Past the last breakpoint no more events occur,
+// unless SetCyclic(true) has been called.
+func NewPiecewiseConstantArrivals(g *RngStream, times, rates []float64) (*ArrivalProcess, error) {
+	if len(rates) == 0 || len(times) != len(rates)+1 {
+		return nil, ErrDimension
+	}
+	return newPiecewiseArrivals(g, arrivalsConstant, times, rates)
+}
+
+// NewPiecewiseLinearArrivals returns the process whose rate is rates[i] at
+// times[i] and linear in between, starting at times[0]. The breakpoints
+// must be increasing, len(times) = len(rates) >= 2, and the rates
+// non-negative with at least one positive. Past the last breakpoint no
+// more events occur, unless SetCyclic(true) has been called.
+func NewPiecewiseLinearArrivals(g *RngStream, times, rates []float64) (*ArrivalProcess, error) {
+	if len(rates) < 2 || len(times) != len(rates) {
+		return nil, ErrDimension
+	}
+	return newPiecewiseArrivals(g, arrivalsLinear, times, rates)
+}
+
+func newPiecewiseArrivals(g *RngStream, kind int, times, rates []float64) (*ArrivalProcess, error) {
+	for i, t := range times {
+		if math.IsNaN(t) || math.IsInf(t, 0) || (i > 0 && !(t > times[i-1])) {
+			return nil, paramError("arrivals: breakpoints must be finite and increasing")
+		}
+	}
+	for _, r := range rates {
+		if !(r >= 0) || math.IsInf(r, 1) {
+			return nil, paramError("arrivals: rates must be finite and >= 0")
+		}
+	}
+	p := &ArrivalProcess{g: g, kind: kind,
+		times: append([]float64(nil), times...),
+		rates: append([]float64(nil), rates...),
+		cum:   make([]float64, len(times)),
+		t:     times[0],
+	}
+	for i := 1; i < len(times); i++ {
+		dt := times[i] - times[i-1]
+		if kind == arrivalsConstant {
+			p.cum[i] = p.cum[i-1] + rates[i-1]*dt
+		} else {
+			p.cum[i] = p.cum[i-1] + (rates[i-1]+rates[i])*dt/2
+		}
+	}
+	if !(p.cum[len(p.cum)-1] > 0) {
+		return nil, paramError("arrivals: rate is zero everywhere")
+	}
+	return p, nil
+}
+
+// SetCyclic sets whether a piecewise rate repeats itself with period
+// times[n] - times[0] (e.g., a daily load curve) instead of dropping to
+// zero after the last breakpoint. It has no effect under thinning.
+func (p *ArrivalProcess) SetCyclic(c bool) {
+	p.cycle = c
+}
+
+// Next returns the time of the next event. It returns +Inf once a
+// non-cyclic piecewise rate has no intensity left.
+func (p *ArrivalProcess) Next() float64 {
+	if p.kind == arrivalsThinning {
+		for {
+			p.t -= math.Log(p.g.RandU01()) / p.rateMax
+			if p.g.RandU01()*p.rateMax <= p.rate(p.t) {
+				return p.t
+			}
+		}
+	}
+	p.s -= math.Log(p.g.RandU01())
+	p.t = p.invert(p.s)
+	return p.t
+}
+
+// invert returns the time at which the cumulative intensity reaches s.
+func (p *ArrivalProcess) invert(s float64) float64 {
+	n := len(p.times) - 1
+	total := p.cum[n]
+	offset := 0.0
+	if s >= total {
+		if !p.cycle {
+			return math.Inf(1)
+		}
+		k := math.Floor(s / total)
+		offset = k * (p.times[n] - p.times[0])
+		s -= k * total
+	}
+	// first breakpoint strictly beyond s, so the segment has positive intensity
+	i := sort.Search(n+1, func(i int) bool { return p.cum[i] > s }) - 1
+	if i >= n {
+		i = n - 1
+	}
+	ds := s - p.cum[i]
+	var tau float64
+	switch {
+	case ds <= 0:
+		tau = 0
+	case p.kind == arrivalsConstant:
+		tau = ds / p.rates[i]
+	default:
+		r0 := p.rates[i]
+		b := (p.rates[i+1] - r0) / (p.times[i+1] - p.times[i])
+		// root of r0 tau + b tau^2/2 = ds, in a form that is stable for b ~ 0
+		tau = 2 * ds / (r0 + math.Sqrt(math.Max(r0*r0+2*b*ds, 0)))
+	}
+	return offset + p.times[i] + tau
+}
+
+// ResetStartSubstream restarts the process at its initial time and resets
+// its stream to the beginning of the current substream, so that the same
+// event times are generated again.
+func (p *ArrivalProcess) ResetStartSubstream() {
+	p.g.ResetStartSubstream()
+	p.restart()
+}
+
+// ResetNextSubstream restarts the process at its initial time and moves
+// its stream to the beginning of the next substream, for the next
+// replication.
+func (p *ArrivalProcess) ResetNextSubstream() {
+	p.g.ResetNextSubstream()
+	p.restart()
+}
+
+func (p *ArrivalProcess) restart() {
+	p.s = 0
+	p.t = 0
+	if p.kind != arrivalsThinning {
+		p.t = p.times[0]
+	}
+}
@@ -0,0 +1,80 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// countArrivals returns the number of events in [0, horizon) over reps
+// replications, each on its own substream.
+func countArrivals(p *ArrivalProcess, horizon float64, reps int) float64 {
+	n := 0
+	for r := 0; r < reps; r++ {
+		for p.Next() < horizon {
+			n++
+		}
+		p.ResetNextSubstream()
+	}
+	return float64(n) / float64(reps)
+}
+
+func TestArrivalProcess(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("arrivals")
+
+	// rate 2 + t on [0, 10]: expected count 20 + 50 = 70
+	lin, err := NewPiecewiseLinearArrivals(g, []float64{0, 10}, []float64{2, 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := countArrivals(lin, 10, 2000); math.Abs(m-70) > 1 {
+		t.Errorf("piecewise linear: mean count %v, wanted 70", m)
+	}
+
+	thin, err := NewThinningArrivals(g, func(t float64) float64 { return 2 + t }, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := countArrivals(thin, 10, 2000); math.Abs(m-70) > 1 {
+		t.Errorf("thinning: mean count %v, wanted 70", m)
+	}
+
+	// diurnal-like curve, repeated over three periods: 3 * (5 + 20 + 5)
+	pc, err := NewPiecewiseConstantArrivals(g, []float64{0, 1, 2, 3}, []float64{5, 20, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := countArrivals(pc, 9, 2000); math.Abs(m-30) > 0.5 {
+		t.Errorf("non-cyclic: mean count %v, wanted 30", m)
+	}
+	pc.SetCyclic(true)
+	if m := countArrivals(pc, 9, 2000); math.Abs(m-90) > 1 {
+		t.Errorf("cyclic: mean count %v, wanted 90", m)
+	}
+
+	// each replication regenerates identical arrivals
+	first := make([]float64, 20)
+	for i := range first {
+		first[i] = lin.Next()
+	}
+	lin.ResetStartSubstream()
+	for i := range first {
+		if x := lin.Next(); x != first[i] {
+			t.Fatalf("event %d: %v after reset, %v before", i, x, first[i])
+		}
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i] < first[i-1] {
+			t.Fatalf("event times decrease: %v", first)
+		}
+	}
+
+	for _, t0 := range []float64{math.NaN(), math.Inf(-1), math.Inf(1)} {
+		if _, err := NewPiecewiseLinearArrivals(g, []float64{t0, 10}, []float64{2, 12}); err == nil {
+			t.Errorf("expected error for first breakpoint %v", t0)
+		}
+	}
+	if _, err := NewPiecewiseConstantArrivals(g, []float64{0, 1}, []float64{0}); err == nil {
+		t.Errorf("expected error for zero rate")
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// bridgeStep fills in W(t_m) given W(t_l) and W(t_r); an index of -1 on
+// the left stands for W(0) = 0, and on the right for "no right end".
+type bridgeStep struct {
+	m, l, r int
+	wl, wr  float64 // weights of W(t_l) and W(t_r) in the conditional mean
+	sd      float64 // conditional standard deviation
+}
+
+// BrownianMotion generates paths, observed on a fixed time grid, of
+// X(t) = x0 + mu t + sigma W(t), or of the geometric Brownian motion
+// S(t) = s0 exp((mu - sigma^2/2) t + sigma W(t)), where W is a standard
+// Brownian motion started at time 0.
+//
+// Every path makes exactly one call to RandU01 per grid point, and each
+// normal increment is obtained by inversion. Path uses the uniforms in
+// time order. BridgePath uses the Brownian bridge construction, which
+// assigns the first uniform to the end of the path and the following ones
+// to successively finer time scales; this concentrates the variance in
+// the first few uniforms, which helps variance reduction and
+// quasi-Monte Carlo methods.
+type BrownianMotion struct {
+	x0, mu, sigma float64
+	geometric     bool
+	times         []float64
+	plan          []bridgeStep
+}
+
+// NewBrownianMotion returns the Brownian motion with initial value x0,
+// drift mu and volatility sigma >= 0, observed at the given times, which
+// must be non-negative and increasing.
+func NewBrownianMotion(x0, mu, sigma float64, times []float64) (*BrownianMotion, error) {
+	return newBrownianMotion(x0, mu, sigma, times, false)
+}
+
+// NewGeometricBrownianMotion returns the geometric Brownian motion with
+// initial value s0 > 0, drift mu and volatility sigma >= 0, observed at the
+// given times, which must be non-negative and increasing. Its mean at time
+// t is s0 exp(mu t).
+func NewGeometricBrownianMotion(s0, mu, sigma float64, times []float64) (*BrownianMotion, error) {
+	if !(s0 > 0) {
+		return nil, paramError("brownian: need s0 > 0")
+	}
+	return newBrownianMotion(s0, mu, sigma, times, true)
+}
+
+func newBrownianMotion(x0, mu, sigma float64, times []float64, geometric bool) (*BrownianMotion, error) {
+	if len(times) == 0 {
+		return nil, ErrDimension
+	}
+	if !(sigma >= 0) || math.IsInf(sigma, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) {
+		return nil, paramError("brownian: need finite mu and sigma >= 0")
+	}
+	if !(times[0] >= 0) {
+		return nil, paramError("brownian: times must be non-negative")
+	}
+	for i := 1; i < len(times); i++ {
+		if !(times[i] > times[i-1]) || math.IsInf(times[i], 1) {
+			return nil, paramError("brownian: times must be finite and increasing")
+		}
+	}
+	b := &BrownianMotion{x0: x0, mu: mu, sigma: sigma, geometric: geometric,
+		times: append([]float64(nil), times...)}
+	b.plan = bridgePlan(b.times)
+	return b, nil
+}
+
+// bridgePlan returns the Brownian bridge steps for the grid, in
+// breadth-first order so that the largest time scales come first.
+func bridgePlan(times []float64) []bridgeStep {
+	n := len(times)
+	at := func(i int) float64 {
+		if i < 0 {
+			return 0
+		}
+		return times[i]
+	}
+	plan := make([]bridgeStep, 0, n)
+	plan = append(plan, bridgeStep{m: n - 1, l: -1, r: -1, sd: math.Sqrt(times[n-1])})
+	type span struct{ l, r int }
+	queue := []span{{-1, n - 1}}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		if s.r-s.l < 2 {
+			continue
+		}
+		m := s.l + (s.r-s.l)/2
+		tl, tm, tr := at(s.l), at(m), at(s.r)
+		plan = append(plan, bridgeStep{m: m, l: s.l, r: s.r,
+			wl: (tr - tm) / (tr - tl),
+			wr: (tm - tl) / (tr - tl),
+			sd: math.Sqrt((tm - tl) * (tr - tm) / (tr - tl)),
+		})
+		queue = append(queue, span{s.l, m}, span{m, s.r})
+	}
+	return plan
+}
+
+// Len returns the number of points of the time grid.
+func (b *BrownianMotion) Len() int {
+	return len(b.times)
+}
+
+// Path writes into dst the values of the process at the grid times,
+// generating the Brownian increments sequentially. dst must have length at
+// least Len().
+func (b *BrownianMotion) Path(g *RngStream, dst []float64) {
+	if len(dst) < len(b.times) {
+		panic(ErrDimension)
+	}
+	w, t := 0.0, 0.0
+	for i, ti := range b.times {
+		w += math.Sqrt(ti-t) * StdNormalInv(g.RandU01())
+		t = ti
+		dst[i] = w
+	}
+	b.transform(dst)
+}
+
+// BridgePath writes into dst the values of the process at the grid times,
+// using the Brownian bridge construction. dst must have length at least
+// Len().
+func (b *BrownianMotion) BridgePath(g *RngStream, dst []float64) {
+	if len(dst) < len(b.times) {
+		panic(ErrDimension)
+	}
+	for _, s := range b.plan {
+		mean := 0.0
+		if s.l >= 0 {
+			mean += s.wl * dst[s.l]
+		}
+		if s.r >= 0 {
+			mean += s.wr * dst[s.r]
+		}
+		dst[s.m] = mean + s.sd*StdNormalInv(g.RandU01())
+	}
+	b.transform(dst)
+}
+
+// transform maps standard Brownian values in dst to the process values.
+func (b *BrownianMotion) transform(dst []float64) {
+	drift := b.mu
+	if b.geometric {
+		drift -= b.sigma * b.sigma / 2
+	}
+	for i, t := range b.times {
+		x := drift*t + b.sigma*dst[i]
+		if b.geometric {
+			dst[i] = b.x0 * math.Exp(x)
+		} else {
+			dst[i] = b.x0 + x
+		}
+	}
+}
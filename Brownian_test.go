@@ -0,0 +1,68 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBrownianMotion(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("brownian")
+
+	times := []float64{0.1, 0.25, 0.5, 0.6, 1, 1.3, 2}
+	bm, err := NewBrownianMotion(1, 0.5, 2, times)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(times)
+	x := make([]float64, n)
+	for _, bridge := range []bool{false, true} {
+		const reps = 40000
+		mean := make([]float64, n)
+		cov := make([]float64, n) // covariance with the last point
+		for r := 0; r < reps; r++ {
+			if bridge {
+				bm.BridgePath(g, x)
+			} else {
+				bm.Path(g, x)
+			}
+			for i := range x {
+				mean[i] += x[i] / reps
+				cov[i] += (x[i] - 1 - 0.5*times[i]) * (x[n-1] - 1 - 0.5*times[n-1]) / reps
+			}
+		}
+		for i, ti := range times {
+			// Cov(X(t_i), X(t_n)) = sigma^2 t_i
+			if math.Abs(mean[i]-(1+0.5*ti)) > 0.05 || math.Abs(cov[i]-4*ti) > 0.15 {
+				t.Errorf("bridge=%v t=%v: mean %v cov %v, wanted %v %v",
+					bridge, ti, mean[i], cov[i], 1+0.5*ti, 4*ti)
+			}
+		}
+	}
+
+	// the bridge draws the end point from the first uniform
+	g.ResetNextSubstream()
+	bm.BridgePath(g, x)
+	g.ResetStartSubstream()
+	want := 1 + 0.5*2 + 2*math.Sqrt(2)*StdNormalInv(g.RandU01())
+	if math.Abs(x[n-1]-want) > 1e-12 {
+		t.Errorf("bridge end point %v, wanted %v", x[n-1], want)
+	}
+
+	gbm, err := NewGeometricBrownianMotion(100, 0.05, 0.3, times)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := 0.0
+	for r := 0; r < 40000; r++ {
+		gbm.BridgePath(g, x)
+		sum += x[n-1] / 40000
+	}
+	if want := 100 * math.Exp(0.1); math.Abs(sum-want) > 1 {
+		t.Errorf("GBM terminal mean %v, wanted %v", sum, want)
+	}
+
+	if _, err := NewBrownianMotion(0, 0, 1, []float64{1, 0.5}); err == nil {
+		t.Errorf("expected error for decreasing times")
+	}
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// CRNExperiment compares system configurations with common random
+// numbers. It owns a set of named streams, one per source of randomness
+// of the model, and a list of configurations, each a function computing
+// one estimate from the streams. For replication r, every stream is put
+// at the beginning of its substream r before each configuration is run,
+// so that all configurations see identical random inputs in the same
+// replication, and independent inputs across replications.
+//
+// For the synchronization to be effective, each configuration should
+// draw every random input from the same stream, whatever the
+// configuration, as described in the paper of L'Ecuyer et al. (2002).
+type CRNExperiment struct {
+	names   []string
+	streams map[string]*RngStream
+	configs []string
+	runs    []func(r int) float64
+}
+
+// NewCRNExperiment returns an experiment with one new stream per name.
+// An error is returned if the names are not distinct.
+func NewCRNExperiment(names ...string) (*CRNExperiment, error) {
+	e := &CRNExperiment{streams: make(map[string]*RngStream)}
+	for _, name := range names {
+		if _, ok := e.streams[name]; ok {
+			return nil, paramError("CRN experiment: duplicate stream %q", name)
+		}
+		e.names = append(e.names, name)
+		e.streams[name] = New(name)
+	}
+	return e, nil
+}
+
+// Stream returns the stream with the given name, or nil if there is none.
+func (e *CRNExperiment) Stream(name string) *RngStream {
+	return e.streams[name]
+}
+
+// AddConfiguration adds a configuration, whose estimate for replication r
+// is computed by run(r) from the experiment's streams.
+func (e *CRNExperiment) AddConfiguration(name string, run func(r int) float64) {
+	e.configs = append(e.configs, name)
+	e.runs = append(e.runs, run)
+}
+
+// Replicate runs every configuration on replication r and returns their
+// estimates, in the order the configurations were added.
+func (e *CRNExperiment) Replicate(r int) []float64 {
+	est := make([]float64, len(e.runs))
+	for i, run := range e.runs {
+		for _, name := range e.names {
+			e.streams[name].resetSubstream(int64(r))
+		}
+		est[i] = run(r)
+	}
+	return est
+}
+
+// Run runs replications 0, ..., n-1, n >= 2, of every configuration.
+func (e *CRNExperiment) Run(n int) *CRNResult {
+	if n < 2 {
+		panic(paramError("CRN experiment: need n >= 2"))
+	}
+	res := &CRNResult{
+		Configurations: append([]string(nil), e.configs...),
+		Estimates:      make([][]float64, len(e.configs)),
+	}
+	for i := range res.Estimates {
+		res.Estimates[i] = make([]float64, n)
+	}
+	for r := 0; r < n; r++ {
+		for i, v := range e.Replicate(r) {
+			res.Estimates[i][r] = v
+		}
+	}
+	return res
+}
+
+// CRNResult holds the estimates of a CRN experiment.
+type CRNResult struct {
+	Configurations []string
+	Estimates      [][]float64 // Estimates[i][r] for configuration i, replication r
+}
+
+// PairedDifference summarizes the differences between two configurations
+// over the replications of a CRN experiment.
+type PairedDifference struct {
+	Differences []float64 // per replication
+	Mean        float64
+	Variance    float64 // sample variance of the differences
+	StdError    float64 // standard error of Mean
+
+	// VarianceReduction is the ratio of the variance of the difference of
+	// two independent estimates, Var(a) + Var(b), to the variance of the
+	// paired difference. Values above 1 mean that CRN helped.
+	VarianceReduction float64
+}
+
+// Difference returns the paired differences a - b between the estimates
+// of the configurations named a and b. It panics if either name is
+// unknown.
+func (res *CRNResult) Difference(a, b string) *PairedDifference {
+	ia, ib := res.index(a), res.index(b)
+	x, y := res.Estimates[ia], res.Estimates[ib]
+	d := &PairedDifference{Differences: make([]float64, len(x))}
+	for r := range x {
+		d.Differences[r] = x[r] - y[r]
+	}
+	d.Mean, d.Variance = meanVariance(d.Differences)
+	d.StdError = math.Sqrt(d.Variance / float64(len(x)))
+	_, va := meanVariance(x)
+	_, vb := meanVariance(y)
+	d.VarianceReduction = (va + vb) / d.Variance
+	return d
+}
+
+func (res *CRNResult) index(name string) int {
+	for i, c := range res.Configurations {
+		if c == name {
+			return i
+		}
+	}
+	panic(paramError("CRN result: unknown configuration %q", name))
+}
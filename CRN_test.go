@@ -0,0 +1,81 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResetSubstream(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("g")
+	h := *g
+	for _, r := range []int64{0, 1, 7, 100} {
+		h.ResetStartStream()
+		for i := int64(0); i < r; i++ {
+			h.ResetNextSubstream()
+		}
+		g.RandU01()
+		g.resetSubstream(r)
+		if a, b := g.RandU01(), h.RandU01(); a != b {
+			t.Errorf("substream %d: %v, wanted %v", r, a, b)
+		}
+	}
+}
+
+func TestCRNExperiment(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	if _, err := NewCRNExperiment("x", "x"); err == nil {
+		t.Errorf("expected error for duplicate stream names")
+	}
+	e, err := NewCRNExperiment("arrivals", "service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	arr, svc := e.Stream("arrivals"), e.Stream("service")
+	if e.Stream("missing") != nil {
+		t.Errorf("unknown stream is not nil")
+	}
+
+	// mean waiting time of 20 customers in a single-server queue, by
+	// Lindley's recursion, for two service rates
+	queue := func(mu float64) func(int) float64 {
+		return func(int) float64 {
+			w, sum := 0.0, 0.0
+			for i := 0; i < 20; i++ {
+				a := -math.Log(arr.RandU01())
+				s := -math.Log(svc.RandU01()) / mu
+				w = math.Max(0, w+s-a)
+				sum += w
+			}
+			return sum / 20
+		}
+	}
+	e.AddConfiguration("slow", queue(1.2))
+	e.AddConfiguration("fast", queue(1.5))
+	e.AddConfiguration("first", func(int) float64 { return arr.RandU01() })
+	e.AddConfiguration("again", func(int) float64 { return arr.RandU01() })
+
+	res := e.Run(500)
+	d := res.Difference("first", "again")
+	for r, v := range d.Differences {
+		if v != 0 {
+			t.Fatalf("replication %d: configurations saw different inputs", r)
+		}
+	}
+	if a, b := res.Estimates[2][0], res.Estimates[2][1]; a == b {
+		t.Errorf("replications 0 and 1 saw identical inputs")
+	}
+
+	d = res.Difference("slow", "fast")
+	if !(d.Mean > 0) || d.Mean < 4*d.StdError {
+		t.Errorf("slow - fast = %v +- %v, expected clearly positive", d.Mean, d.StdError)
+	}
+	if d.VarianceReduction < 5 {
+		t.Errorf("variance reduction %v, expected CRN to help markedly", d.VarianceReduction)
+	}
+
+	// a replication can be rerun on its own
+	if got := e.Replicate(3)[0]; got != res.Estimates[0][3] {
+		t.Errorf("replication 3 rerun gives %v, wanted %v", got, res.Estimates[0][3])
+	}
+}
@@ -0,0 +1,395 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Mixture is a finite mixture of distributions: with probability
+// weights[i], a variate is drawn from components[i].
+//
+// By default Sample uses composition: one call to RandU01 selects the
+// component, which then generates the variate with its own Sample method.
+// After SetInversion(true), Sample uses conditional inversion instead:
+// the uniform u selects component i, with cum[i-1] <= u < cum[i] for the
+// cumulative weights cum, and the variate is the quantile of component i
+// at (u - cum[i-1])/weights[i]. This is exact for any components, uses
+// exactly one uniform per variate, and is monotone in u within each
+// component, which keeps common random numbers synchronized across
+// configurations.
+//
+// Mixture has a PDF method, and thus satisfies Continuous, but its
+// density is only defined, and its Quantile only uses it, when all the
+// components are Continuous; PDF returns NaN otherwise.
+type Mixture struct {
+	weights    []float64 // normalized
+	cum        []float64 // cumulative weights
+	components []Distribution
+	continuous bool // all components are Continuous
+	discrete   bool // all components are Discrete
+	inversion  bool
+}
+
+// NewMixture returns the mixture of the given components with the given
+// non-negative weights, which are normalized to sum to one.
+func NewMixture(weights []float64, components []Distribution) (*Mixture, error) {
+	if len(weights) == 0 || len(weights) != len(components) {
+		return nil, ErrDimension
+	}
+	sum := 0.0
+	for _, w := range weights {
+		if !(w >= 0) || math.IsInf(w, 1) {
+			return nil, paramError("mixture: need finite weights >= 0")
+		}
+		sum += w
+	}
+	if !(sum > 0) {
+		return nil, paramError("mixture: weights sum to zero")
+	}
+	m := &Mixture{
+		weights:    make([]float64, len(weights)),
+		cum:        make([]float64, len(weights)),
+		components: append([]Distribution(nil), components...),
+		continuous: true,
+		discrete:   true,
+	}
+	for _, d := range components {
+		if _, ok := d.(Continuous); !ok {
+			m.continuous = false
+		}
+		if _, ok := d.(Discrete); !ok {
+			m.discrete = false
+		}
+	}
+	c := 0.0
+	for i, w := range weights {
+		m.weights[i] = w / sum
+		c += m.weights[i]
+		m.cum[i] = c
+	}
+	m.cum[len(m.cum)-1] = 1
+	return m, nil
+}
+
+// SetInversion selects sampling by conditional inversion (inv = true) or
+// by composition (inv = false, the default).
+func (m *Mixture) SetInversion(inv bool) {
+	m.inversion = inv
+}
+
+// CDF returns the distribution function at x.
+func (m *Mixture) CDF(x float64) float64 {
+	f := 0.0
+	for i, d := range m.components {
+		f += m.weights[i] * d.CDF(x)
+	}
+	return f
+}
+
+// PDF returns the density at x. It is only meaningful if all the
+// components are Continuous, and returns NaN otherwise.
+func (m *Mixture) PDF(x float64) float64 {
+	if !m.continuous {
+		return math.NaN()
+	}
+	f := 0.0
+	for i, d := range m.components {
+		f += m.weights[i] * d.(Continuous).PDF(x)
+	}
+	return f
+}
+
+// Quantile returns the inverse of the distribution function at u. If all
+// the components are Discrete, it is the smallest integer k with
+// CDF(k) > u, found by bisection. Otherwise it is computed numerically,
+// which is exact only for continuous components.
+func (m *Mixture) Quantile(u float64) float64 {
+	// the quantile lies between the smallest and the largest quantiles of
+	// the components at u, and their weighted mean is a starting point
+	// that stays finite when the mean of a component is infinite
+	lo, hi := math.Inf(1), math.Inf(-1)
+	x0, w := 0.0, 0.0
+	for i, d := range m.components {
+		if m.weights[i] > 0 {
+			q := d.Quantile(u)
+			lo = math.Min(lo, q)
+			hi = math.Max(hi, q)
+			if !math.IsInf(q, 0) {
+				x0 += m.weights[i] * q
+				w += m.weights[i]
+			}
+		}
+	}
+	if lo == hi {
+		return lo
+	}
+	if m.discrete {
+		// CDF(lo - 1) <= u < CDF(hi)
+		for hi-lo >= 1 {
+			mid := math.Floor(lo/2 + hi/2)
+			if m.CDF(mid) > u {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		return hi
+	}
+	if w > 0 {
+		x0 /= w
+	}
+	var pdf func(float64) float64
+	if m.continuous {
+		pdf = m.PDF
+	}
+	return invertCDF(m.CDF, pdf, u, x0, lo, hi)
+}
+
+// Sample returns a variate, by composition or by conditional inversion
+// (see SetInversion).
+func (m *Mixture) Sample(g *RngStream) float64 {
+	if m.inversion {
+		return m.conditionalQuantile(g.RandU01())
+	}
+	return m.components[m.pick(g.RandU01())].Sample(g)
+}
+
+// conditionalQuantile returns the variate generated by conditional
+// inversion from the uniform u.
+func (m *Mixture) conditionalQuantile(u float64) float64 {
+	i := m.pick(u)
+	below := 0.0
+	if i > 0 {
+		below = m.cum[i-1]
+	}
+	v := (u - below) / m.weights[i]
+	if v > 1 {
+		v = 1 // rounding, or u beyond the last cumulative weight
+	}
+	return m.components[i].Quantile(v)
+}
+
+// pick returns the component selected by the uniform u.
+func (m *Mixture) pick(u float64) int {
+	for i, c := range m.cum {
+		if u < c {
+			return i
+		}
+	}
+	return len(m.cum) - 1
+}
+
+// Mean returns the weighted mean of the component means.
+func (m *Mixture) Mean() float64 {
+	mean := 0.0
+	for i, d := range m.components {
+		if m.weights[i] > 0 {
+			mean += m.weights[i] * d.Mean()
+		}
+	}
+	return mean
+}
+
+// Variance returns the variance of the mixture, the weighted second
+// moments of the components minus the squared mean.
+func (m *Mixture) Variance() float64 {
+	mean := m.Mean()
+	v := 0.0
+	for i, d := range m.components {
+		if m.weights[i] > 0 {
+			mi := d.Mean() - mean
+			v += m.weights[i] * (d.Variance() + mi*mi)
+		}
+	}
+	return v
+}
+
+// Shift is the distribution of X + c, for X with distribution d.
+type Shift struct {
+	d Distribution
+	c float64
+}
+
+// NewShift returns the distribution of X + c, for X with distribution d.
+func NewShift(d Distribution, c float64) (*Shift, error) {
+	if math.IsNaN(c) || math.IsInf(c, 0) {
+		return nil, paramError("shift: need a finite shift")
+	}
+	return &Shift{d: d, c: c}, nil
+}
+
+// CDF returns the distribution function at x.
+func (s *Shift) CDF(x float64) float64 { return s.d.CDF(x - s.c) }
+
+// Quantile returns the inverse of the distribution function at u.
+func (s *Shift) Quantile(u float64) float64 { return s.d.Quantile(u) + s.c }
+
+// Sample returns a variate of d, shifted; it uses the same uniforms as d.
+func (s *Shift) Sample(g *RngStream) float64 { return s.d.Sample(g) + s.c }
+
+// Mean returns the mean of d plus c.
+func (s *Shift) Mean() float64 { return s.d.Mean() + s.c }
+
+// Variance returns the variance of d.
+func (s *Shift) Variance() float64 { return s.d.Variance() }
+
+// Scale is the distribution of a X, for a > 0 and X with distribution d.
+type Scale struct {
+	d Distribution
+	a float64
+}
+
+// NewScale returns the distribution of a X, for a > 0 and X with
+// distribution d.
+func NewScale(d Distribution, a float64) (*Scale, error) {
+	if !(a > 0) || math.IsInf(a, 1) {
+		return nil, paramError("scale: need a finite factor > 0")
+	}
+	return &Scale{d: d, a: a}, nil
+}
+
+// CDF returns the distribution function at x.
+func (s *Scale) CDF(x float64) float64 { return s.d.CDF(x / s.a) }
+
+// Quantile returns the inverse of the distribution function at u.
+func (s *Scale) Quantile(u float64) float64 { return s.a * s.d.Quantile(u) }
+
+// Sample returns a variate of d, scaled; it uses the same uniforms as d.
+func (s *Scale) Sample(g *RngStream) float64 { return s.a * s.d.Sample(g) }
+
+// Mean returns a times the mean of d.
+func (s *Scale) Mean() float64 { return s.a * s.d.Mean() }
+
+// Variance returns a^2 times the variance of d.
+func (s *Scale) Variance() float64 { return s.a * s.a * s.d.Variance() }
+
+// Max is the distribution of the maximum of n independent variates of a
+// distribution d; its distribution function is F^n.
+type Max struct {
+	d Distribution
+	n int
+}
+
+// NewMax returns the distribution of the maximum of n >= 1 independent
+// variates of d.
+func NewMax(d Distribution, n int) (*Max, error) {
+	if n < 1 {
+		return nil, paramError("max: need n >= 1")
+	}
+	return &Max{d: d, n: n}, nil
+}
+
+// CDF returns the distribution function at x.
+func (m *Max) CDF(x float64) float64 {
+	return math.Pow(m.d.CDF(x), float64(m.n))
+}
+
+// Quantile returns the inverse of the distribution function at u, that
+// is, the quantile of d at u^(1/n).
+func (m *Max) Quantile(u float64) float64 {
+	return m.d.Quantile(math.Pow(u, 1/float64(m.n)))
+}
+
+// Sample returns a variate generated by inversion from one call to
+// RandU01, rather than from n draws of d.
+func (m *Max) Sample(g *RngStream) float64 {
+	return SampleInversion(m, g)
+}
+
+// Mean returns the mean, computed by numerical integration of the
+// quantile function.
+func (m *Max) Mean() float64 {
+	mean, _ := quantileMoments(m.Quantile)
+	return mean
+}
+
+// Variance returns the variance, computed by numerical integration of the
+// quantile function.
+func (m *Max) Variance() float64 {
+	_, v := quantileMoments(m.Quantile)
+	return v
+}
+
+// Min is the distribution of the minimum of n independent variates of a
+// distribution d; its distribution function is 1 - (1-F)^n.
+type Min struct {
+	d Distribution
+	n int
+}
+
+// NewMin returns the distribution of the minimum of n >= 1 independent
+// variates of d.
+func NewMin(d Distribution, n int) (*Min, error) {
+	if n < 1 {
+		return nil, paramError("min: need n >= 1")
+	}
+	return &Min{d: d, n: n}, nil
+}
+
+// CDF returns the distribution function at x.
+func (m *Min) CDF(x float64) float64 {
+	return -math.Expm1(float64(m.n) * math.Log1p(-m.d.CDF(x)))
+}
+
+// Quantile returns the inverse of the distribution function at u, that
+// is, the quantile of d at 1 - (1-u)^(1/n).
+func (m *Min) Quantile(u float64) float64 {
+	return m.d.Quantile(-math.Expm1(math.Log1p(-u) / float64(m.n)))
+}
+
+// Sample returns a variate generated by inversion from one call to
+// RandU01, rather than from n draws of d.
+func (m *Min) Sample(g *RngStream) float64 {
+	return SampleInversion(m, g)
+}
+
+// Mean returns the mean, computed by numerical integration of the
+// quantile function.
+func (m *Min) Mean() float64 {
+	mean, _ := quantileMoments(m.Quantile)
+	return mean
+}
+
+// Variance returns the variance, computed by numerical integration of the
+// quantile function.
+func (m *Min) Variance() float64 {
+	_, v := quantileMoments(m.Quantile)
+	return v
+}
+
+// Convolution is the distribution of the sum of n independent variates
+// of a distribution d. Its distribution function has no closed form in
+// general, so Convolution provides the moments and Sample only, and is a
+// Sampler but not a Distribution.
+type Convolution struct {
+	d Distribution
+	n int
+}
+
+// NewConvolution returns the distribution of the sum of n >= 1
+// independent variates of d.
+func NewConvolution(d Distribution, n int) (*Convolution, error) {
+	if n < 1 {
+		return nil, paramError("convolution: need n >= 1")
+	}
+	return &Convolution{d: d, n: n}, nil
+}
+
+// Sample returns the sum of n variates of d, drawn in turn with d's
+// Sample method.
+func (c *Convolution) Sample(g *RngStream) float64 {
+	sum := 0.0
+	for i := 0; i < c.n; i++ {
+		sum += c.d.Sample(g)
+	}
+	return sum
+}
+
+// Mean returns n times the mean of d.
+func (c *Convolution) Mean() float64 {
+	return float64(c.n) * c.d.Mean()
+}
+
+// Variance returns n times the variance of d.
+func (c *Convolution) Variance() float64 {
+	return float64(c.n) * c.d.Variance()
+}
@@ -0,0 +1,170 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+var (
+	_ Distribution = (*Mixture)(nil)
+	_ Distribution = (*Shift)(nil)
+	_ Distribution = (*Scale)(nil)
+	_ Distribution = (*Max)(nil)
+	_ Distribution = (*Min)(nil)
+)
+
+func TestCombinators(t *testing.T) {
+	norm, _ := NewNormal(-1, 0.5)
+	gam, _ := NewGamma(3, 1)
+	unif, _ := NewUniform(0, 1)
+	wide, _ := NewNormal(2, 1.5)
+	mix, err := NewMixture([]float64{1, 3}, []Distribution{norm, wide})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shift, _ := NewShift(gam, 2.5)
+	scale, _ := NewScale(norm, 3)
+	max, _ := NewMax(norm, 5)
+	min, _ := NewMin(gam, 4)
+
+	for _, d := range []Distribution{mix, shift, scale, max, min} {
+		m, v := quantileMoments(d.Quantile)
+		if math.Abs(m-d.Mean()) > 1e-6*(1+math.Abs(m)) || math.Abs(v-d.Variance()) > 1e-5*(1+v) {
+			t.Errorf("%T: moments (%v, %v), quadrature gives (%v, %v)", d, d.Mean(), d.Variance(), m, v)
+		}
+		for _, u := range []float64{1e-6, 0.1, 0.5, 0.77, 0.999} {
+			if got := d.CDF(d.Quantile(u)); math.Abs(got-u) > 1e-9 {
+				t.Errorf("%T: CDF(Quantile(%v)) = %v", d, u, got)
+			}
+		}
+	}
+
+	// order statistics of uniforms
+	umax, _ := NewMax(unif, 4)
+	umin, _ := NewMin(unif, 4)
+	if math.Abs(umax.Mean()-0.8) > 1e-9 || math.Abs(umin.Mean()-0.2) > 1e-9 {
+		t.Errorf("uniform max/min means %v, %v, wanted 0.8, 0.2", umax.Mean(), umin.Mean())
+	}
+
+	// mixture moments from the components
+	wantMean := 0.25*-1 + 0.75*2
+	wantVar := 0.25*(0.25+1) + 0.75*(2.25+4) - wantMean*wantMean
+	if math.Abs(mix.Mean()-wantMean) > 1e-12 || math.Abs(mix.Variance()-wantVar) > 1e-12 {
+		t.Errorf("mixture moments (%v, %v), wanted (%v, %v)", mix.Mean(), mix.Variance(), wantMean, wantVar)
+	}
+
+	if _, err := NewMixture([]float64{1}, []Distribution{norm, gam}); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if _, err := NewMixture([]float64{0, 0}, []Distribution{norm, gam}); err == nil {
+		t.Errorf("expected error for zero weights")
+	}
+	if _, err := NewScale(norm, -1); err == nil {
+		t.Errorf("expected error for negative scale")
+	}
+	if _, err := NewMax(norm, 0); err == nil {
+		t.Errorf("expected error for n = 0")
+	}
+}
+
+func TestMixtureSampling(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("mixture")
+	expo, _ := NewExponential(1)
+	norm, _ := NewNormal(4, 1)
+	mix, _ := NewMixture([]float64{0.3, 0.7}, []Distribution{expo, norm})
+
+	const n = 100000
+	for _, inv := range []bool{false, true} {
+		mix.SetInversion(inv)
+		g.ResetStartStream()
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += mix.Sample(g)
+		}
+		se := math.Sqrt(mix.Variance() / n)
+		if m := sum / n; math.Abs(m-mix.Mean()) > 4*se {
+			t.Errorf("inversion %v: sample mean %v, wanted %v", inv, m, mix.Mean())
+		}
+	}
+
+	// by conditional inversion, exactly one uniform per variate
+	mix.SetInversion(true)
+	g.ResetStartStream()
+	c := *g
+	for i := 0; i < 1000; i++ {
+		x := mix.Sample(g)
+		u := c.RandU01()
+		want := expo.Quantile(u / 0.3)
+		if u >= 0.3 {
+			want = norm.Quantile((u - 0.3) / 0.7)
+		}
+		if math.Abs(x-want) > 1e-12*(1+math.Abs(want)) {
+			t.Fatalf("variate %d: %v, wanted %v", i, x, want)
+		}
+	}
+
+	// discrete components: quantiles and inverted variates are integers
+	// of the support, with the mixture probabilities
+	b1, _ := NewBinomial(10, 0.3)
+	b2, _ := NewBinomial(20, 0.8)
+	dmix, _ := NewMixture([]float64{0.4, 0.6}, []Distribution{b1, b2})
+	for _, u := range []float64{0, 0.01, 0.3, 0.4, 0.5, 0.9, 0.999} {
+		k := dmix.Quantile(u)
+		if k != math.Floor(k) || dmix.CDF(k) <= u || dmix.CDF(k-1) > u {
+			t.Errorf("discrete mixture: Quantile(%v) = %v is not the smallest k with CDF(k) > u", u, k)
+		}
+	}
+	dmix.SetInversion(true)
+	counts := make([]float64, 21)
+	for i := 0; i < n; i++ {
+		k := dmix.Sample(g)
+		if k != math.Floor(k) || k < 0 || k > 20 {
+			t.Fatalf("discrete mixture: variate %v outside the support", k)
+		}
+		counts[int(k)]++
+	}
+	for k := range counts {
+		p := 0.4*b1.PMF(k) + 0.6*b2.PMF(k)
+		if f := counts[k] / n; math.Abs(f-p) > 4*math.Sqrt(p*(1-p)/n)+1e-9 {
+			t.Errorf("discrete mixture: frequency of %d is %v, wanted %v", k, f, p)
+		}
+	}
+
+	// a component with an infinite mean
+	levy, _ := NewLevy(1, 1)
+	shifted, _ := NewShift(expo, 2)
+	heavy, _ := NewMixture([]float64{0.5, 0.5}, []Distribution{levy, shifted})
+	prev := math.Inf(-1)
+	for _, u := range []float64{0.1, 0.3, 0.5, 0.7, 0.9} {
+		x := heavy.Quantile(u)
+		if math.IsInf(x, 0) || !(x > prev) {
+			t.Errorf("heavy-tailed mixture: Quantile(%v) = %v", u, x)
+		}
+		if c := heavy.CDF(x); math.Abs(c-u) > 1e-9 {
+			t.Errorf("heavy-tailed mixture: CDF(Quantile(%v)) = %v", u, c)
+		}
+		prev = x
+	}
+}
+
+func TestConvolution(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("convolution")
+	expo, _ := NewExponential(2)
+	conv, err := NewConvolution(expo, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	erlang, _ := NewGamma(3, 0.5)
+	if conv.Mean() != erlang.Mean() || conv.Variance() != erlang.Variance() {
+		t.Errorf("moments (%v, %v), wanted (%v, %v)", conv.Mean(), conv.Variance(), erlang.Mean(), erlang.Variance())
+	}
+	p := []float64{0.1, 0.5, 0.9}
+	q := quantiles(func() float64 { return conv.Sample(g) }, 50000, p)
+	for i, pi := range p {
+		if got := erlang.CDF(q[i]); math.Abs(got-pi) > 0.01 {
+			t.Errorf("empirical %v-quantile %v has Erlang CDF %v", pi, q[i], got)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// ControlVariates collects the outputs Y of independent replications
+// together with q control variables C_1, ..., C_q of known means
+// mu_1, ..., mu_q, computed from the same random inputs, and estimates
+// E[Y] by the controlled estimator mean(Y) - beta^T (mean(C) - mu). The
+// coefficients beta are estimated by the multiple regression of Y on the
+// controls, which minimizes the variance for large samples.
+//
+// With estimated coefficients, the estimator is the intercept of the
+// regression Y = a + beta^T (C - mu) + e, and its standard error and
+// confidence interval are those of the intercept, from the Student t
+// distribution with n-q-1 degrees of freedom; they are exact when Y and
+// C are jointly normal, as in Lavenberg and Welch (1981), and
+// asymptotically valid otherwise.
+type ControlVariates struct {
+	mu    []float64
+	y     []float64
+	c     [][]float64 // c[i] holds the controls of replication i
+	level float64
+}
+
+// ControlVariateResult holds the outcome of a controlled estimation.
+type ControlVariateResult struct {
+	Coefficients     []float64 // estimated beta
+	Mean             float64   // controlled estimate of E[Y]
+	StdError         float64   // standard error of Mean
+	Low, High        float64   // confidence interval
+	Level            float64   // confidence level of the interval
+	DegreesOfFreedom int       // n-q-1
+
+	PlainMean     float64 // mean of the outputs
+	PlainStdError float64 // standard error of PlainMean
+
+	// VarianceReduction is the ratio of the squared standard errors of
+	// PlainMean and Mean. It is +Inf when the controls explain the outputs
+	// exactly, so that StdError is zero, and 1 when the outputs are
+	// constant.
+	VarianceReduction float64
+}
+
+// NewControlVariates returns a collector for controls with the given
+// known means, at least one, with confidence level 0.95.
+func NewControlVariates(means ...float64) (*ControlVariates, error) {
+	if len(means) == 0 {
+		return nil, ErrDimension
+	}
+	return &ControlVariates{mu: append([]float64(nil), means...), level: 0.95}, nil
+}
+
+// SetLevel sets the confidence level, 0 < level < 1, 0.95 by default.
+func (cv *ControlVariates) SetLevel(level float64) {
+	if !(level > 0 && level < 1) {
+		panic(paramError("control variates: need 0 < level < 1"))
+	}
+	cv.level = level
+}
+
+// Add records the output y of one replication with its controls c, one
+// per known mean.
+func (cv *ControlVariates) Add(y float64, c ...float64) {
+	if len(c) != len(cv.mu) {
+		panic(ErrDimension)
+	}
+	cv.y = append(cv.y, y)
+	cv.c = append(cv.c, append([]float64(nil), c...))
+}
+
+// Len returns the number of replications recorded.
+func (cv *ControlVariates) Len() int {
+	return len(cv.y)
+}
+
+// Estimate returns the controlled estimate from the replications
+// recorded. It needs n >= q+2 replications and controls whose sample
+// covariance matrix is non-singular; otherwise the statistics of the
+// controlled estimator are NaN.
+func (cv *ControlVariates) Estimate() *ControlVariateResult {
+	n, q := len(cv.y), len(cv.mu)
+	res := &ControlVariateResult{
+		Coefficients:     make([]float64, q),
+		Level:            cv.level,
+		DegreesOfFreedom: n - q - 1,
+		Mean:             math.NaN(),
+		StdError:         math.NaN(),
+		Low:              math.NaN(),
+		High:             math.NaN(),
+	}
+	var v float64
+	res.PlainMean, v = meanVariance(cv.y)
+	res.PlainStdError = math.Sqrt(v / float64(n))
+	for k := range res.Coefficients {
+		res.Coefficients[k] = math.NaN()
+	}
+	if n < q+2 {
+		return res
+	}
+
+	// centered cross-products of the controls and with the outputs
+	cbar := make([]float64, q)
+	for _, c := range cv.c {
+		for k, v := range c {
+			cbar[k] += v / float64(n)
+		}
+	}
+	scc := newMatrix(q, q)
+	scy := make([]float64, q)
+	for i, c := range cv.c {
+		dy := cv.y[i] - res.PlainMean
+		for k := range c {
+			dk := c[k] - cbar[k]
+			scy[k] += dk * dy
+			for l := 0; l <= k; l++ {
+				scc[k][l] += dk * (c[l] - cbar[l])
+			}
+		}
+	}
+	for k := range scc {
+		for l := 0; l < k; l++ {
+			scc[l][k] = scc[k][l]
+		}
+	}
+	chol, ok := cholesky(scc)
+	if !ok {
+		return res
+	}
+	beta := solveCholesky(chol, scy)
+	copy(res.Coefficients, beta)
+
+	// intercept of the regression on C - mu, and its standard error
+	dev := make([]float64, q)
+	res.Mean = res.PlainMean
+	for k := range dev {
+		dev[k] = cbar[k] - cv.mu[k]
+		res.Mean -= beta[k] * dev[k]
+	}
+	sse := 0.0
+	for i, c := range cv.c {
+		e := cv.y[i] - res.PlainMean
+		for k := range c {
+			e -= beta[k] * (c[k] - cbar[k])
+		}
+		sse += e * e
+	}
+	s2 := sse / float64(n-q-1)
+	w := solveCholesky(chol, dev)
+	quad := 0.0
+	for k := range dev {
+		quad += dev[k] * w[k]
+	}
+	res.StdError = math.Sqrt(s2 * (1/float64(n) + quad))
+	h := tHalfWidth(res.StdError, float64(n-q-1), cv.level)
+	res.Low, res.High = res.Mean-h, res.Mean+h
+	switch {
+	case res.PlainStdError == 0:
+		res.VarianceReduction = 1
+	case res.StdError == 0:
+		res.VarianceReduction = math.Inf(1)
+	default:
+		res.VarianceReduction = res.PlainStdError * res.PlainStdError / (res.StdError * res.StdError)
+	}
+	return res
+}
+
+// ReplicateWithControls runs n replications of rep, the function
+// computing the output and the controls of one replication from the given
+// streams, and returns the controlled estimate for controls with the
+// given known means. Replication r runs from the start of the current
+// substream of every stream, so that its output and controls come from
+// the same random inputs, and the streams are then moved to their next
+// substream, as in AntitheticPair.
+func ReplicateWithControls(streams []*RngStream, n int, means []float64, rep func(r int) (y float64, c []float64)) (*ControlVariateResult, error) {
+	cv, err := NewControlVariates(means...)
+	if err != nil {
+		return nil, err
+	}
+	if n < len(means)+2 {
+		return nil, paramError("control variates: need n >= q+2 replications")
+	}
+	for r := 0; r < n; r++ {
+		for _, g := range streams {
+			g.ResetStartSubstream()
+		}
+		y, c := rep(r)
+		cv.Add(y, c...)
+		for _, g := range streams {
+			g.ResetNextSubstream()
+		}
+	}
+	return cv.Estimate(), nil
+}
@@ -0,0 +1,110 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestControlVariates(t *testing.T) {
+	// one control: beta = Sxy / Sxx and the standard error of the
+	// intercept of a simple regression
+	x := []float64{0.1, 0.4, 0.35, 0.8, 0.6, 0.2}
+	y := []float64{1.2, 1.4, 1.5, 2.3, 1.7, 1.1}
+	cv, err := NewControlVariates(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range x {
+		cv.Add(y[i], x[i])
+	}
+	res := cv.Estimate()
+	mx, vx := meanVariance(x)
+	my, _ := meanVariance(y)
+	sxy := 0.0
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+	}
+	sxx := vx * 5
+	beta := sxy / sxx
+	sse := 0.0
+	for i := range x {
+		e := y[i] - my - beta*(x[i]-mx)
+		sse += e * e
+	}
+	se := math.Sqrt(sse / 4 * (1.0/6 + (mx-0.5)*(mx-0.5)/sxx))
+	if math.Abs(res.Coefficients[0]-beta) > 1e-12 || math.Abs(res.Mean-(my-beta*(mx-0.5))) > 1e-12 ||
+		math.Abs(res.StdError-se) > 1e-12 || res.DegreesOfFreedom != 4 {
+		t.Errorf("beta %v, mean %v, se %v, df %d; wanted %v, %v, %v, 4",
+			res.Coefficients[0], res.Mean, res.StdError, res.DegreesOfFreedom, beta, my-beta*(mx-0.5), se)
+	}
+	if h := res.High - res.Mean; math.Abs(h/se-2.776445) > 1e-5 {
+		t.Errorf("half-width %v standard errors, wanted t(4) quantile 2.776445", h/se)
+	}
+
+	// a control that explains the outputs exactly, and constant outputs
+	exact, _ := NewControlVariates(2)
+	constant, _ := NewControlVariates(2)
+	for _, c := range []float64{1, 3, 1, 3} {
+		exact.Add(c, c)
+		constant.Add(5, c)
+	}
+	if r := exact.Estimate(); r.StdError != 0 || !math.IsInf(r.VarianceReduction, 1) {
+		t.Errorf("exact control: se %v, variance reduction %v, wanted 0 and +Inf", r.StdError, r.VarianceReduction)
+	}
+	if r := constant.Estimate(); r.VarianceReduction != 1 {
+		t.Errorf("constant outputs: variance reduction %v, wanted 1", r.VarianceReduction)
+	}
+
+	short, _ := NewControlVariates(0, 0)
+	short.Add(1, 2, 3)
+	short.Add(2, 1, 4)
+	short.Add(3, 5, 1)
+	if r := short.Estimate(); !math.IsNaN(r.Mean) || r.PlainMean != 2 {
+		t.Errorf("estimate %v from 3 replications and 2 controls, plain mean %v", r.Mean, r.PlainMean)
+	}
+	if _, err := NewControlVariates(); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
+
+func TestReplicateWithControls(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("cv")
+	exact := math.E - 1
+	// E[exp(U)] with the controls U and U^2, from the same uniform
+	rep := func(r int) (float64, []float64) {
+		u := g.RandU01()
+		return math.Exp(u), []float64{u, u * u}
+	}
+	res, err := ReplicateWithControls([]*RngStream{g}, 1000, []float64{0.5, 1.0 / 3}, rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.Mean-exact) > 4*res.StdError {
+		t.Errorf("estimate %v +- %v, wanted %v", res.Mean, res.StdError, exact)
+	}
+	// the quadratic fit of exp on [0,1] leaves little variance
+	if res.VarianceReduction < 500 {
+		t.Errorf("variance reduction %v", res.VarianceReduction)
+	}
+
+	// coverage of the t interval over independent experiments
+	covered := 0
+	const experiments = 400
+	for e := 0; e < experiments; e++ {
+		res, _ := ReplicateWithControls([]*RngStream{g}, 20, []float64{0.5}, func(r int) (float64, []float64) {
+			u := g.RandU01()
+			return math.Exp(u), []float64{u}
+		})
+		if res.Low <= exact && exact <= res.High {
+			covered++
+		}
+	}
+	if c := float64(covered) / experiments; c < 0.9 || c > 0.99 {
+		t.Errorf("coverage %v, wanted 0.95", c)
+	}
+
+	if _, err := ReplicateWithControls([]*RngStream{g}, 3, []float64{0.5, 0.3}, rep); err == nil {
+		t.Errorf("expected error for n < q+2")
+	}
+}
@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Copula generates vectors of dependent uniforms. Every copula of the
+// package derives its output from a fixed number of calls to RandU01,
+// documented for each type, with all auxiliary variates generated by
+// inversion, so copula samples stay synchronized across streams used for
+// common random numbers. The uniforms can be given arbitrary marginals
+// with SampleJoint.
+type Copula interface {
+	Dim() int
+	Sample(g *RngStream, u []float64)
+}
+
+// SampleJoint writes into dst a vector with dependence structure c and
+// marginal distributions marginals, by pushing a sample of c through the
+// marginal quantile functions. dst and marginals must have length at least
+// c.Dim().
+func SampleJoint(c Copula, marginals []Invertible, g *RngStream, dst []float64) {
+	n := c.Dim()
+	if len(dst) < n || len(marginals) < n {
+		panic(ErrDimension)
+	}
+	c.Sample(g, dst[:n])
+	for i := 0; i < n; i++ {
+		dst[i] = marginals[i].Quantile(dst[i])
+	}
+}
+
+// checkCorrelation returns the Cholesky factor of the correlation matrix
+// corr.
+func checkCorrelation(corr [][]float64) ([][]float64, error) {
+	if len(corr) < 2 || !isSquare(corr) {
+		return nil, ErrDimension
+	}
+	for i := range corr {
+		if math.Abs(corr[i][i]-1) > 1e-12 {
+			return nil, paramError("copula: correlation matrix needs a unit diagonal")
+		}
+	}
+	l, ok := cholesky(corr)
+	if !ok {
+		return nil, ErrNotPositiveDefinite
+	}
+	return l, nil
+}
+
+// GaussianCopula is the copula of a multivariate normal vector with a
+// given correlation matrix.
+type GaussianCopula struct {
+	chol [][]float64
+}
+
+// NewGaussianCopula returns the Gaussian copula with correlation matrix
+// corr, which must be positive definite with a unit diagonal.
+func NewGaussianCopula(corr [][]float64) (*GaussianCopula, error) {
+	l, err := checkCorrelation(corr)
+	if err != nil {
+		return nil, err
+	}
+	return &GaussianCopula{chol: l}, nil
+}
+
+// Dim returns the dimension of the copula.
+func (c *GaussianCopula) Dim() int {
+	return len(c.chol)
+}
+
+// Sample writes a sample of the copula into u. It makes exactly Dim()
+// calls to RandU01.
+func (c *GaussianCopula) Sample(g *RngStream, u []float64) {
+	correlatedNormals(g, c.chol, u)
+	for i := range c.chol {
+		u[i] = StdNormalCDF(u[i])
+	}
+}
+
+// correlatedNormals writes L z into x, for z a vector of standard normals
+// generated by inversion. z is allocated per call, as in MultiNormal, so
+// that a copula can be sampled concurrently with different streams.
+func correlatedNormals(g *RngStream, l [][]float64, x []float64) {
+	n := len(l)
+	if len(x) < n {
+		panic(ErrDimension)
+	}
+	z := make([]float64, n)
+	for i := range z {
+		z[i] = StdNormalInv(g.RandU01())
+	}
+	for i := 0; i < n; i++ {
+		sum := 0.0
+		for k := 0; k <= i; k++ {
+			sum += l[i][k] * z[k]
+		}
+		x[i] = sum
+	}
+}
+
+// StudentTCopula is the copula of a multivariate Student t vector with a
+// given correlation matrix and nu degrees of freedom. It has tail
+// dependence, unlike the Gaussian copula.
+type StudentTCopula struct {
+	chol [][]float64
+	chi2 *Gamma
+	t    *StudentT
+}
+
+// NewStudentTCopula returns the Student t copula with correlation matrix
+// corr, positive definite with a unit diagonal, and nu > 0 degrees of
+// freedom.
+func NewStudentTCopula(corr [][]float64, nu float64) (*StudentTCopula, error) {
+	if !(nu > 0) || math.IsInf(nu, 1) {
+		return nil, paramError("t copula: need finite nu > 0")
+	}
+	l, err := checkCorrelation(corr)
+	if err != nil {
+		return nil, err
+	}
+	chi2, _ := NewGamma(nu/2, 2)
+	t, _ := NewStudentT(nu)
+	return &StudentTCopula{chol: l, chi2: chi2, t: t}, nil
+}
+
+// Dim returns the dimension of the copula.
+func (c *StudentTCopula) Dim() int {
+	return len(c.chol)
+}
+
+// Sample writes a sample of the copula into u. It makes exactly Dim()+1
+// calls to RandU01: one per normal, then one for the chi-square variate,
+// which is generated by (numerical) inversion.
+func (c *StudentTCopula) Sample(g *RngStream, u []float64) {
+	correlatedNormals(g, c.chol, u)
+	s := math.Sqrt(c.t.nu / c.chi2.Quantile(g.RandU01()))
+	for i := range c.chol {
+		u[i] = c.t.CDF(u[i] * s)
+	}
+}
+
+// ClaytonCopula is the d-dimensional Clayton copula with parameter
+// theta > 0, whose Kendall's tau is theta/(theta+2). It has lower tail
+// dependence.
+type ClaytonCopula struct {
+	d     int
+	theta float64
+	frail *Gamma
+}
+
+// NewClaytonCopula returns the Clayton copula of dimension d >= 2 with
+// parameter theta > 0.
+func NewClaytonCopula(d int, theta float64) (*ClaytonCopula, error) {
+	if d < 2 {
+		return nil, ErrDimension
+	}
+	if !(theta > 0) || math.IsInf(theta, 1) {
+		return nil, paramError("clayton copula: need finite theta > 0")
+	}
+	frail, _ := NewGamma(1/theta, 1)
+	return &ClaytonCopula{d: d, theta: theta, frail: frail}, nil
+}
+
+// Dim returns the dimension of the copula.
+func (c *ClaytonCopula) Dim() int {
+	return c.d
+}
+
+// Sample writes a sample of the copula into u, with the Marshall-Olkin
+// algorithm. It makes exactly Dim()+1 calls to RandU01: the first for the
+// gamma frailty, generated by (numerical) inversion, then one per
+// component.
+func (c *ClaytonCopula) Sample(g *RngStream, u []float64) {
+	if len(u) < c.d {
+		panic(ErrDimension)
+	}
+	v := c.frail.Quantile(g.RandU01())
+	for i := 0; i < c.d; i++ {
+		e := -math.Log(g.RandU01())
+		u[i] = math.Exp(-math.Log1p(e/v) / c.theta)
+	}
+}
+
+// GumbelCopula is the d-dimensional Gumbel copula with parameter
+// theta >= 1, whose Kendall's tau is 1 - 1/theta. It has upper tail
+// dependence; theta = 1 is the independence copula.
+type GumbelCopula struct {
+	d     int
+	theta float64
+	frail *AlphaStable
+}
+
+// NewGumbelCopula returns the Gumbel copula of dimension d >= 2 with
+// parameter theta >= 1.
+func NewGumbelCopula(d int, theta float64) (*GumbelCopula, error) {
+	if d < 2 {
+		return nil, ErrDimension
+	}
+	if !(theta >= 1) || math.IsInf(theta, 1) {
+		return nil, paramError("gumbel copula: need finite theta >= 1")
+	}
+	c := &GumbelCopula{d: d, theta: theta}
+	if theta > 1 {
+		// positive stable frailty with Laplace transform exp(-s^(1/theta))
+		a := 1 / theta
+		c.frail, _ = NewAlphaStable(a, 1, math.Pow(math.Cos(math.Pi*a/2), theta), 0)
+	}
+	return c, nil
+}
+
+// Dim returns the dimension of the copula.
+func (c *GumbelCopula) Dim() int {
+	return c.d
+}
+
+// Sample writes a sample of the copula into u, with the Marshall-Olkin
+// algorithm. It makes exactly Dim()+2 calls to RandU01: two for the
+// positive stable frailty (see AlphaStable), then one per component.
+func (c *GumbelCopula) Sample(g *RngStream, u []float64) {
+	if len(u) < c.d {
+		panic(ErrDimension)
+	}
+	v := 1.0
+	if c.frail != nil {
+		v = c.frail.Sample(g)
+	} else {
+		// keep the number of uniforms independent of theta
+		g.RandU01()
+		g.RandU01()
+	}
+	for i := 0; i < c.d; i++ {
+		e := -math.Log(g.RandU01())
+		u[i] = math.Exp(-math.Pow(e/v, 1/c.theta))
+	}
+}
+
+// FrankCopula is the d-dimensional Frank copula with parameter theta > 0.
+// It has no tail dependence.
+type FrankCopula struct {
+	d     int
+	theta float64
+}
+
+// NewFrankCopula returns the Frank copula of dimension d >= 2 with
+// parameter theta > 0.
+func NewFrankCopula(d int, theta float64) (*FrankCopula, error) {
+	if d < 2 {
+		return nil, ErrDimension
+	}
+	if !(theta > 0) || math.IsInf(theta, 1) {
+		return nil, paramError("frank copula: need finite theta > 0")
+	}
+	return &FrankCopula{d: d, theta: theta}, nil
+}
+
+// Dim returns the dimension of the copula.
+func (c *FrankCopula) Dim() int {
+	return c.d
+}
+
+// Sample writes a sample of the copula into u, with the Marshall-Olkin
+// algorithm. It makes exactly Dim()+2 calls to RandU01: two for the
+// logarithmic frailty (Kemp's LK algorithm), then one per component.
+func (c *FrankCopula) Sample(g *RngStream, u []float64) {
+	if len(u) < c.d {
+		panic(ErrDimension)
+	}
+	v := logSeriesKemp(g.RandU01(), g.RandU01(), -c.theta)
+	em := math.Expm1(-c.theta)
+	for i := 0; i < c.d; i++ {
+		e := -math.Log(g.RandU01())
+		u[i] = -math.Log1p(math.Exp(-e/v)*em) / c.theta
+	}
+}
+
+// logSeriesKemp returns a variate of the logarithmic series distribution
+// P(V = k) = -p^k / (k log(1-p)), given h = log(1-p) < 0, from the two
+// uniforms u1 and u2 with the LK algorithm of Kemp (1981).
+func logSeriesKemp(u1, u2, h float64) float64 {
+	p := -math.Expm1(h)
+	if u1 > p {
+		return 1
+	}
+	q := -math.Expm1(h * u2)
+	if u1 < q*q {
+		return math.Floor(1 + math.Log(u1)/math.Log(q))
+	}
+	if u1 > q {
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,168 @@
+package rngstream
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+// kendallTau returns the sample Kendall's tau of the pairs (x[i], y[i]).
+func kendallTau(x, y []float64) float64 {
+	n := len(x)
+	s := 0.0
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if (x[i]-x[j])*(y[i]-y[j]) > 0 {
+				s++
+			} else {
+				s--
+			}
+		}
+	}
+	return 2 * s / float64(n*(n-1))
+}
+
+func TestStudentT(t *testing.T) {
+	for _, nu := range []float64{1, 2, 3.5, 30} {
+		d, _ := NewStudentT(nu)
+		for _, u := range []float64{1e-9, 0.01, 0.3, 0.5, 0.8, 0.999} {
+			if got := d.CDF(d.Quantile(u)); math.Abs(got-u) > 1e-10*math.Max(math.Min(u, 1-u), 1e-3) {
+				t.Errorf("nu=%v: CDF(Quantile(%v)) = %v", nu, u, got)
+			}
+		}
+	}
+	d, _ := NewStudentT(10)
+	if q := d.Quantile(0.975); math.Abs(q-2.228138851986274) > 1e-13 {
+		t.Errorf("t(10) 0.975-quantile = %v", q)
+	}
+}
+
+func TestCopulas(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("copula")
+
+	// Debye function D1(theta) for Frank's Kendall tau
+	debye1 := func(theta float64) float64 {
+		const m = 2000
+		h := theta / m
+		s := 0.0
+		for i := 0; i <= m; i++ {
+			x := float64(i) * h
+			f := 1.0
+			if x > 0 {
+				f = x / math.Expm1(x)
+			}
+			w := 2.0
+			if i == 0 || i == m {
+				w = 1
+			} else if i%2 == 1 {
+				w = 4
+			}
+			s += w * f
+		}
+		return s * h / 3 / theta
+	}
+
+	rho := 0.6
+	corr := [][]float64{{1, rho, 0.2}, {rho, 1, 0.1}, {0.2, 0.1, 1}}
+	gauss, _ := NewGaussianCopula(corr)
+	tcop, _ := NewStudentTCopula(corr, 4)
+	clayton, _ := NewClaytonCopula(3, 2)
+	gumbel, _ := NewGumbelCopula(3, 2.5)
+	frank, _ := NewFrankCopula(3, 5)
+
+	cases := []struct {
+		name  string
+		c     Copula
+		calls int
+		tau   float64
+	}{
+		{"gaussian", gauss, 3, 2 / math.Pi * math.Asin(rho)},
+		{"student", tcop, 4, 2 / math.Pi * math.Asin(rho)},
+		{"clayton", clayton, 4, 2.0 / 4},
+		{"gumbel", gumbel, 5, 1 - 1/2.5},
+		{"frank", frank, 5, 1 - 4/5.0*(1-debye1(5))},
+	}
+	const n = 2000
+	u := make([]float64, 3)
+	for _, c := range cases {
+		x, y := make([]float64, n), make([]float64, n)
+		mean := 0.0
+		for i := 0; i < n; i++ {
+			c.c.Sample(g, u)
+			for _, ui := range u {
+				if !(ui >= 0 && ui <= 1) {
+					t.Fatalf("%s: uniform %v out of range", c.name, ui)
+				}
+			}
+			x[i], y[i] = u[0], u[1]
+			mean += u[2] / n
+		}
+		if tau := kendallTau(x, y); math.Abs(tau-c.tau) > 0.04 {
+			t.Errorf("%s: Kendall's tau %v, wanted %v", c.name, tau, c.tau)
+		}
+		if math.Abs(mean-0.5) > 0.02 {
+			t.Errorf("%s: marginal mean %v", c.name, mean)
+		}
+
+		// fixed number of uniforms per sample
+		g.ResetNextSubstream()
+		c.c.Sample(g, u)
+		next := g.RandU01()
+		g.ResetStartSubstream()
+		for i := 0; i < c.calls; i++ {
+			g.RandU01()
+		}
+		if g.RandU01() != next {
+			t.Errorf("%s: sample does not use %d uniforms", c.name, c.calls)
+		}
+	}
+
+	// concurrent sampling with one stream per goroutine gives the same
+	// samples as sequential sampling
+	for _, c := range cases {
+		streams := make([]RngStream, 4)
+		for i := range streams {
+			streams[i] = *g
+			g.ResetNextSubstream()
+		}
+		sample := func(s RngStream) []float64 {
+			out := make([]float64, 3*100)
+			for k := 0; k < 100; k++ {
+				c.c.Sample(&s, out[3*k:])
+			}
+			return out
+		}
+		got := make([][]float64, len(streams))
+		var wg sync.WaitGroup
+		for i := range streams {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				got[i] = sample(streams[i])
+			}(i)
+		}
+		wg.Wait()
+		for i := range streams {
+			want := sample(streams[i])
+			for k := range want {
+				if got[i][k] != want[k] {
+					t.Fatalf("%s: concurrent sample differs from sequential one", c.name)
+				}
+			}
+		}
+	}
+
+	expo, _ := NewExponential(1)
+	norm, _ := NewNormal(10, 1)
+	unif, _ := NewUniform(0, 1)
+	x := make([]float64, 3)
+	SampleJoint(gauss, []Invertible{expo, norm, unif}, g, x)
+	if x[0] <= 0 || x[2] <= 0 || x[2] >= 1 {
+		t.Errorf("SampleJoint gave %v", x)
+	}
+
+	if _, err := NewGaussianCopula([][]float64{{2, 0}, {0, 1}}); err == nil {
+		t.Errorf("expected error for non-unit diagonal")
+	}
+}
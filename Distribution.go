@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Sampler is implemented by anything that generates univariate variates
+// from a stream.
+type Sampler interface {
+	Sample(g *RngStream) float64
+}
+
+// Distribution is implemented by the univariate distributions of this
+// package. Besides sampling, it gives access to the distribution function,
+// its inverse and the first two moments, so that generic code can sample
+// by inversion, check simulation output against theoretical moments, or
+// run goodness-of-fit tests.
+//
+// Unless documented otherwise, Sample is equivalent to
+// SampleInversion(d, g) and makes exactly one call to RandU01.
+type Distribution interface {
+	Sampler
+	Invertible
+	Mean() float64
+	Variance() float64
+}
+
+// Continuous is a Distribution with a density.
+type Continuous interface {
+	Distribution
+	PDF(x float64) float64
+}
+
+// Discrete is a Distribution over the integers, with a probability mass
+// function. Its Quantile and Sample methods return integral values.
+type Discrete interface {
+	Distribution
+	PMF(k int) float64
+}
+
+// SampleInversion returns d.Quantile(g.RandU01()), a variate of d
+// generated by inversion from exactly one uniform. This is the natural
+// Sample method for any distribution that provides its quantile function,
+// and the one that keeps streams synchronized under common random numbers.
+// As g may be any UniformStream, it also draws variates of every
+// Invertible distribution from the points of a PointStream.
+func SampleInversion(d Invertible, g UniformStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// quantileMoments computes the mean and variance of a distribution from
+// its quantile function q, as the integrals over (0, 1) of q(u) and
+// q(u)^2, using tanh-sinh quadrature, which copes with the singularities
+// of q at both ends. The results are meaningless if the moments do not
+// exist.
+func quantileMoments(q func(u float64) float64) (mean, variance float64) {
+	const h = 1.0 / 32
+	var s1, s2 float64
+	for k := -96; k <= 96; k++ {
+		t := float64(k) * h
+		s := math.Pi / 2 * math.Sinh(t)
+		ch := math.Cosh(s)
+		w := h * math.Pi / 4 * math.Cosh(t) / (ch * ch)
+		// 0.5 + 0.5 tanh(s), written to keep precision near 0
+		u := 1 / (1 + math.Exp(-2*s))
+		if u <= 0 || u >= 1 {
+			continue
+		}
+		x := q(u)
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			continue
+		}
+		s1 += w * x
+		s2 += w * x * x
+	}
+	return s1, s2 - s1*s1
+}
+
+// invertCDF returns the x in [lo, hi] with cdf(x) = u, for a continuous
+// non-decreasing cdf, starting from the guess x0. Either bound may be
+// infinite. A guess that is not finite or lies outside [lo, hi] is
+// replaced by a finite point of the interval. Newton steps are used when
+// a density pdf is given and stays inside the current bracket; bisection
+// is used otherwise.
+func invertCDF(cdf, pdf func(float64) float64, u, x0, lo, hi float64) float64 {
+	if u <= 0 {
+		return lo
+	}
+	if u >= 1 {
+		return hi
+	}
+	if math.IsNaN(x0) || math.IsInf(x0, 0) || x0 < lo || x0 > hi {
+		switch {
+		case lo <= 0 && hi >= 0:
+			x0 = 0
+		case math.IsInf(hi, 1):
+			x0 = lo + math.Max(1, math.Abs(lo))
+		case math.IsInf(lo, -1):
+			x0 = hi - math.Max(1, math.Abs(hi))
+		default:
+			x0 = lo/2 + hi/2
+		}
+	}
+
+	// bracket the root
+	a, b := lo, hi
+	if math.IsInf(a, -1) {
+		step := math.Max(1, math.Abs(x0))
+		for a = x0 - step; cdf(a) > u && !math.IsInf(a, -1); a = x0 - step {
+			step *= 2
+		}
+	}
+	if math.IsInf(b, 1) {
+		step := math.Max(1, math.Abs(x0))
+		for b = x0 + step; cdf(b) < u && !math.IsInf(b, 1); b = x0 + step {
+			step *= 2
+		}
+	}
+
+	x := x0
+	if x <= a || x >= b {
+		x = a + (b-a)/2
+	}
+	for i := 0; i < 500; i++ {
+		f := cdf(x) - u
+		if f == 0 {
+			return x
+		}
+		if f < 0 {
+			a = x
+		} else {
+			b = x
+		}
+		next := a + (b-a)/2
+		if pdf != nil {
+			if p := pdf(x); p > 0 {
+				if n := x - f/p; n > a && n < b {
+					next = n
+				}
+			}
+		}
+		if math.Abs(next-x) <= 4e-16*math.Abs(x) || b-a <= 4e-16*(math.Abs(a)+math.Abs(b)) {
+			return next
+		}
+		x = next
+	}
+	return x
+}
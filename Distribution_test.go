@@ -0,0 +1,95 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// All univariate distributions of the package implement the interfaces.
+var (
+	_ Continuous   = (*Normal)(nil)
+	_ Continuous   = (*LogNormal)(nil)
+	_ Continuous   = (*Exponential)(nil)
+	_ Continuous   = (*Weibull)(nil)
+	_ Continuous   = (*Uniform)(nil)
+	_ Continuous   = (*Gamma)(nil)
+	_ Continuous   = (*StudentT)(nil)
+	_ Continuous   = (*Beta)(nil)
+	_ Discrete     = (*Binomial)(nil)
+	_ Discrete     = (*Zipf)(nil)
+	_ Distribution = (*Truncated)(nil)
+	_ Distribution = (*CustomInvertible)(nil)
+)
+
+func TestDistributionMoments(t *testing.T) {
+	norm, _ := NewNormal(1, 2)
+	logn, _ := NewLogNormal(0.2, 0.4)
+	expo, _ := NewExponential(0.5)
+	weib, _ := NewWeibull(2.5, 1.5)
+	unif, _ := NewUniform(-1, 3)
+	gam1, _ := NewGamma(0.5, 2)
+	gam2, _ := NewGamma(7, 0.3)
+	beta, _ := NewBeta(0.7, 2.5)
+	tr, _ := NewTruncated(norm, 0, 2)
+
+	for _, d := range []Distribution{norm, logn, expo, weib, unif, gam1, gam2, beta, tr} {
+		m, v := quantileMoments(d.Quantile)
+		if math.Abs(m-d.Mean()) > 1e-6*(1+math.Abs(m)) || math.Abs(v-d.Variance()) > 1e-5*(1+v) {
+			t.Errorf("%T: moments (%v, %v), quadrature gives (%v, %v)", d, d.Mean(), d.Variance(), m, v)
+		}
+		for _, u := range []float64{1e-6, 0.1, 0.5, 0.77, 0.999} {
+			if got := d.CDF(d.Quantile(u)); math.Abs(got-u) > 1e-9 {
+				t.Errorf("%T: CDF(Quantile(%v)) = %v", d, u, got)
+			}
+		}
+	}
+
+	// closed form for the normal truncated to [0, 2]: mu + sigma (phi(a)-phi(b))/Z
+	phi := func(z float64) float64 { return math.Exp(-z*z/2) / math.Sqrt(2*math.Pi) }
+	a, b := -0.5, 0.5
+	want := 1 + 2*(phi(a)-phi(b))/(StdNormalCDF(b)-StdNormalCDF(a))
+	if math.Abs(tr.Mean()-want) > 1e-9 {
+		t.Errorf("truncated normal mean = %v, wanted %v", tr.Mean(), want)
+	}
+}
+
+func TestBinomialDistribution(t *testing.T) {
+	d, _ := NewBinomial(30, 0.35)
+	cum := 0.0
+	for k := 0; k <= 30; k++ {
+		cum += d.PMF(k)
+		if math.Abs(d.CDF(float64(k))-cum) > 1e-12 {
+			t.Errorf("CDF(%d) = %v, sum of PMF = %v", k, d.CDF(float64(k)), cum)
+		}
+	}
+	for _, u := range []float64{0.01, 0.3, 0.5, 0.9, 0.9999} {
+		k := d.Quantile(u)
+		if d.CDF(k) <= u || (k > 0 && d.CDF(k-1) > u) {
+			t.Errorf("Quantile(%v) = %v is not the smallest k with CDF(k) > u", u, k)
+		}
+	}
+
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("binomial")
+	sum := 0.0
+	for i := 0; i < 20000; i++ {
+		sum += d.Sample(g)
+	}
+	if math.Abs(sum/20000-d.Mean()) > 0.05 {
+		t.Errorf("sample mean %v, wanted %v", sum/20000, d.Mean())
+	}
+}
+
+func TestInvertCDF(t *testing.T) {
+	// exponential shifted to [5, +Inf) and reflected to (-Inf, -5]
+	right := func(x float64) float64 { return -math.Expm1(-(x - 5)) }
+	left := func(x float64) float64 { return math.Exp(x + 5) }
+	for _, x0 := range []float64{math.Inf(1), math.Inf(-1), math.NaN(), 0} {
+		if x, want := invertCDF(right, nil, 0.5, x0, 5, math.Inf(1)), 5+math.Ln2; math.Abs(x-want) > 1e-12 {
+			t.Errorf("x0 = %v: right tail quantile %v, wanted %v", x0, x, want)
+		}
+		if x, want := invertCDF(left, nil, 0.5, x0, math.Inf(-1), -5), -5-math.Ln2; math.Abs(x-want) > 1e-12 {
+			t.Errorf("x0 = %v: left tail quantile %v, wanted %v", x0, x, want)
+		}
+	}
+}
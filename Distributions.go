@@ -0,0 +1,746 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrParameter is returned (wrapped) when a distribution is built with
+// invalid parameters.
+var ErrParameter = errors.New("rngstream: invalid distribution parameter")
+
+func paramError(format string, a ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrParameter}, a...)...)
+}
+
+// Normal is the normal distribution with mean mu and standard
+// deviation sigma.
+type Normal struct {
+	mu, sigma float64
+}
+
+// NewNormal returns the normal distribution with mean mu and standard
+// deviation sigma > 0.
+func NewNormal(mu, sigma float64) (*Normal, error) {
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) {
+		return nil, paramError("normal: need finite mu and sigma > 0")
+	}
+	return &Normal{mu: mu, sigma: sigma}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Normal) CDF(x float64) float64 {
+	return StdNormalCDF((x - d.mu) / d.sigma)
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *Normal) Quantile(u float64) float64 {
+	return d.mu + d.sigma*StdNormalInv(u)
+}
+
+// Survival returns 1 - CDF(x), accurate in the upper tail.
+func (d *Normal) Survival(x float64) float64 {
+	return StdNormalCDF((d.mu - x) / d.sigma)
+}
+
+// InverseSurvival returns the inverse of the survival function at p.
+func (d *Normal) InverseSurvival(p float64) float64 {
+	return d.mu - d.sigma*StdNormalInv(p)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Normal) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// PDF returns the density at x.
+func (d *Normal) PDF(x float64) float64 {
+	z := (x - d.mu) / d.sigma
+	return math.Exp(-z*z/2) / (d.sigma * math.Sqrt(2*math.Pi))
+}
+
+// Mean returns the mean mu.
+func (d *Normal) Mean() float64 {
+	return d.mu
+}
+
+// Variance returns sigma^2.
+func (d *Normal) Variance() float64 {
+	return d.sigma * d.sigma
+}
+
+// LogNormal is the distribution of exp(X), where X is normal with mean
+// mu and standard deviation sigma.
+type LogNormal struct {
+	mu, sigma float64
+}
+
+// NewLogNormal returns the lognormal distribution whose logarithm has mean
+// mu and standard deviation sigma > 0.
+func NewLogNormal(mu, sigma float64) (*LogNormal, error) {
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) {
+		return nil, paramError("lognormal: need finite mu and sigma > 0")
+	}
+	return &LogNormal{mu: mu, sigma: sigma}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *LogNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return StdNormalCDF((math.Log(x) - d.mu) / d.sigma)
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *LogNormal) Quantile(u float64) float64 {
+	return math.Exp(d.mu + d.sigma*StdNormalInv(u))
+}
+
+// Survival returns 1 - CDF(x), accurate in the upper tail.
+func (d *LogNormal) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return StdNormalCDF((d.mu - math.Log(x)) / d.sigma)
+}
+
+// InverseSurvival returns the inverse of the survival function at p.
+func (d *LogNormal) InverseSurvival(p float64) float64 {
+	return math.Exp(d.mu - d.sigma*StdNormalInv(p))
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *LogNormal) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// PDF returns the density at x.
+func (d *LogNormal) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	z := (math.Log(x) - d.mu) / d.sigma
+	return math.Exp(-z*z/2) / (x * d.sigma * math.Sqrt(2*math.Pi))
+}
+
+// Mean returns exp(mu + sigma^2/2).
+func (d *LogNormal) Mean() float64 {
+	return math.Exp(d.mu + d.sigma*d.sigma/2)
+}
+
+// Variance returns (exp(sigma^2) - 1) exp(2 mu + sigma^2).
+func (d *LogNormal) Variance() float64 {
+	s2 := d.sigma * d.sigma
+	return math.Expm1(s2) * math.Exp(2*d.mu+s2)
+}
+
+// RandLogNormal returns a lognormal variate, exp(N(mu, sigma^2)), generated
+// by inversion. Makes one call to RandU01.
+func (g *RngStream) RandLogNormal(mu, sigma float64) float64 {
+	return math.Exp(g.RandNormal(mu, sigma))
+}
+
+// Exponential is the exponential distribution with the given rate
+// (the inverse of its mean).
+type Exponential struct {
+	rate float64
+}
+
+// NewExponential returns the exponential distribution with rate > 0.
+func NewExponential(rate float64) (*Exponential, error) {
+	if !(rate > 0) || math.IsInf(rate, 1) {
+		return nil, paramError("exponential: need finite rate > 0")
+	}
+	return &Exponential{rate: rate}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Exponential) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-d.rate * x)
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *Exponential) Quantile(u float64) float64 {
+	return -math.Log1p(-u) / d.rate
+}
+
+// Survival returns 1 - CDF(x), exp(-rate x) for x > 0.
+func (d *Exponential) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return math.Exp(-d.rate * x)
+}
+
+// InverseSurvival returns the inverse of the survival function at p.
+func (d *Exponential) InverseSurvival(p float64) float64 {
+	return -math.Log(p) / d.rate
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Exponential) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// PDF returns the density at x.
+func (d *Exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.rate * math.Exp(-d.rate*x)
+}
+
+// Mean returns 1/rate.
+func (d *Exponential) Mean() float64 {
+	return 1 / d.rate
+}
+
+// Variance returns 1/rate^2.
+func (d *Exponential) Variance() float64 {
+	return 1 / (d.rate * d.rate)
+}
+
+// Weibull is the Weibull distribution with the given shape and scale,
+// whose distribution function is 1 - exp(-(x/scale)^shape) for x > 0.
+type Weibull struct {
+	shape, scale float64
+}
+
+// NewWeibull returns the Weibull distribution with shape > 0 and
+// scale > 0.
+func NewWeibull(shape, scale float64) (*Weibull, error) {
+	if !(shape > 0) || !(scale > 0) || math.IsInf(shape, 1) || math.IsInf(scale, 1) {
+		return nil, paramError("weibull: need finite shape > 0 and scale > 0")
+	}
+	return &Weibull{shape: shape, scale: scale}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Weibull) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return -math.Expm1(-math.Pow(x/d.scale, d.shape))
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *Weibull) Quantile(u float64) float64 {
+	return d.scale * math.Pow(-math.Log1p(-u), 1/d.shape)
+}
+
+// Survival returns 1 - CDF(x), exp(-(x/scale)^shape) for x > 0.
+func (d *Weibull) Survival(x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	return math.Exp(-math.Pow(x/d.scale, d.shape))
+}
+
+// InverseSurvival returns the inverse of the survival function at p.
+func (d *Weibull) InverseSurvival(p float64) float64 {
+	return d.scale * math.Pow(-math.Log(p), 1/d.shape)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Weibull) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// PDF returns the density at x.
+func (d *Weibull) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	z := x / d.scale
+	return d.shape / d.scale * math.Pow(z, d.shape-1) * math.Exp(-math.Pow(z, d.shape))
+}
+
+// Mean returns scale * Gamma(1 + 1/shape).
+func (d *Weibull) Mean() float64 {
+	return d.scale * math.Gamma(1+1/d.shape)
+}
+
+// Variance returns scale^2 (Gamma(1 + 2/shape) - Gamma(1 + 1/shape)^2).
+func (d *Weibull) Variance() float64 {
+	g1 := math.Gamma(1 + 1/d.shape)
+	return d.scale * d.scale * (math.Gamma(1+2/d.shape) - g1*g1)
+}
+
+// Uniform is the continuous uniform distribution over (a, b).
+type Uniform struct {
+	a, b float64
+}
+
+// NewUniform returns the uniform distribution over (a, b), with a < b.
+func NewUniform(a, b float64) (*Uniform, error) {
+	if !(a < b) || math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return nil, paramError("uniform: need finite a < b")
+	}
+	return &Uniform{a: a, b: b}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Uniform) CDF(x float64) float64 {
+	switch {
+	case x <= d.a:
+		return 0
+	case x >= d.b:
+		return 1
+	}
+	return (x - d.a) / (d.b - d.a)
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *Uniform) Quantile(u float64) float64 {
+	return d.a + u*(d.b-d.a)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Uniform) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// PDF returns the density at x.
+func (d *Uniform) PDF(x float64) float64 {
+	if x < d.a || x > d.b {
+		return 0
+	}
+	return 1 / (d.b - d.a)
+}
+
+// Mean returns (a + b)/2.
+func (d *Uniform) Mean() float64 {
+	return (d.a + d.b) / 2
+}
+
+// Variance returns (b - a)^2/12.
+func (d *Uniform) Variance() float64 {
+	return (d.b - d.a) * (d.b - d.a) / 12
+}
+
+// Gamma is the gamma distribution with the given shape and scale.
+type Gamma struct {
+	shape, scale float64
+}
+
+// NewGamma returns the gamma distribution with shape > 0 and scale > 0.
+func NewGamma(shape, scale float64) (*Gamma, error) {
+	if !(shape > 0) || !(scale > 0) || math.IsInf(shape, 1) || math.IsInf(scale, 1) {
+		return nil, paramError("gamma: need finite shape > 0 and scale > 0")
+	}
+	return &Gamma{shape: shape, scale: scale}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Gamma) CDF(x float64) float64 {
+	return regIncGammaP(d.shape, x/d.scale)
+}
+
+// PDF returns the density at x.
+func (d *Gamma) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x == 0 {
+		switch {
+		case d.shape < 1:
+			return math.Inf(1)
+		case d.shape == 1:
+			return 1 / d.scale
+		}
+		return 0
+	}
+	lg, _ := math.Lgamma(d.shape)
+	z := x / d.scale
+	return math.Exp((d.shape-1)*math.Log(z)-z-lg) / d.scale
+}
+
+// Quantile returns the inverse of the distribution function at u,
+// computed numerically.
+func (d *Gamma) Quantile(u float64) float64 {
+	if u <= 0 {
+		return 0
+	}
+	// Wilson-Hilferty starting point
+	c := 1 / (9 * d.shape)
+	x0 := 1 - c + StdNormalInv(u)*math.Sqrt(c)
+	x0 = d.shape * x0 * x0 * x0
+	if !(x0 > 0) {
+		x0 = math.Pow(u*math.Gamma(d.shape+1), 1/d.shape)
+	}
+	z := invertCDF(func(z float64) float64 { return regIncGammaP(d.shape, z) },
+		func(z float64) float64 { return d.PDF(z*d.scale) * d.scale },
+		u, x0, 0, math.Inf(1))
+	return d.scale * z
+}
+
+// Sample returns a variate generated with RandGamma. Unlike most other
+// distributions of the package, this is a rejection method that makes a
+// random number of calls to RandU01; use SampleInversion instead when
+// common random numbers require one uniform per variate.
+func (d *Gamma) Sample(g *RngStream) float64 {
+	return g.RandGamma(d.shape, d.scale)
+}
+
+// Mean returns shape * scale.
+func (d *Gamma) Mean() float64 {
+	return d.shape * d.scale
+}
+
+// Variance returns shape * scale^2.
+func (d *Gamma) Variance() float64 {
+	return d.shape * d.scale * d.scale
+}
+
+// RandGamma returns a gamma variate with the given shape and scale, both
+// positive, using the method of Marsaglia and Tsang (2000) with normal
+// variates generated by inversion. This is a rejection method: the number
+// of calls to RandU01 is random (two per trial, plus one if shape < 1),
+// with an acceptance probability above 0.95 for shape >= 1.
+func (g *RngStream) RandGamma(shape, scale float64) float64 {
+	return scale * math.Exp(g.randLogGamma(shape))
+}
+
+// randLogGamma returns the logarithm of a Gamma(shape, 1) variate. Working
+// in logs keeps very small shapes from underflowing to zero.
+func (g *RngStream) randLogGamma(shape float64) float64 {
+	if !(shape > 0) {
+		return math.NaN()
+	}
+	if shape < 1 {
+		u := g.RandU01()
+		return math.Log(g.gammaMT(shape+1)) + math.Log(u)/shape
+	}
+	return math.Log(g.gammaMT(shape))
+}
+
+// gammaMT is the Marsaglia-Tsang sampler for Gamma(shape, 1), shape >= 1.
+func (g *RngStream) gammaMT(shape float64) float64 {
+	d := shape - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := StdNormalInv(g.RandU01())
+		v := 1 + c*x
+		u := g.RandU01()
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
+
+// RandBinomial returns a binomial variate, the number of successes in n
+// trials with success probability p, generated by inversion. Makes one
+// call to RandU01. The search starts at the mode, so its cost grows like
+// the standard deviation rather than like n.
+func (g *RngStream) RandBinomial(n int, p float64) int {
+	return binomialInv(g.RandU01(), n, p)
+}
+
+// binomialInv returns the smallest k with P[X <= k] > u for X binomial
+// with parameters n and p.
+func binomialInv(u float64, n int, p float64) int {
+	if n <= 0 || p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return n
+	}
+	q := 1 - p
+	m := int(float64(n+1) * p)
+	if m > n {
+		m = n
+	}
+	lgn, _ := math.Lgamma(float64(n + 1))
+	lgm, _ := math.Lgamma(float64(m + 1))
+	lgnm, _ := math.Lgamma(float64(n - m + 1))
+	pm := math.Exp(lgn - lgm - lgnm + float64(m)*math.Log(p) + float64(n-m)*math.Log1p(-p))
+
+	// probability of {X < m}, summed downward from the mode until the
+	// terms no longer matter
+	below := 0.0
+	pk := pm
+	for k := m; k > 0; k-- {
+		pk *= float64(k) / float64(n-k+1) * q / p
+		below += pk
+		if pk <= below*1e-17 {
+			break
+		}
+	}
+
+	if u >= below {
+		cum := below + pm
+		pk = pm
+		for k := m; k < n; k++ {
+			if u < cum {
+				return k
+			}
+			pk *= float64(n-k) / float64(k+1) * p / q
+			if pk == 0 {
+				return k
+			}
+			cum += pk
+		}
+		return n
+	}
+	cum := below
+	pk = pm
+	for k := m; k > 0; k-- {
+		pk *= float64(k) / float64(n-k+1) * q / p
+		cum -= pk
+		if u >= cum {
+			return k - 1
+		}
+	}
+	return 0
+}
+
+// Binomial is the distribution of the number of successes in n
+// independent trials with success probability p.
+type Binomial struct {
+	n int
+	p float64
+}
+
+// NewBinomial returns the binomial distribution with n >= 0 trials and
+// success probability 0 <= p <= 1.
+func NewBinomial(n int, p float64) (*Binomial, error) {
+	if n < 0 || !(p >= 0 && p <= 1) {
+		return nil, paramError("binomial: need n >= 0 and 0 <= p <= 1")
+	}
+	return &Binomial{n: n, p: p}, nil
+}
+
+// PMF returns the probability of k successes.
+func (d *Binomial) PMF(k int) float64 {
+	if k < 0 || k > d.n {
+		return 0
+	}
+	if d.p == 0 || d.p == 1 {
+		if (d.p == 0 && k == 0) || (d.p == 1 && k == d.n) {
+			return 1
+		}
+		return 0
+	}
+	lgn, _ := math.Lgamma(float64(d.n + 1))
+	lgk, _ := math.Lgamma(float64(k + 1))
+	lgnk, _ := math.Lgamma(float64(d.n - k + 1))
+	return math.Exp(lgn - lgk - lgnk + float64(k)*math.Log(d.p) + float64(d.n-k)*math.Log1p(-d.p))
+}
+
+// CDF returns the probability of at most x successes.
+func (d *Binomial) CDF(x float64) float64 {
+	k := math.Floor(x)
+	switch {
+	case k < 0:
+		return 0
+	case k >= float64(d.n):
+		return 1
+	case d.p == 0:
+		return 1
+	case d.p == 1:
+		return 0
+	}
+	return regIncBeta(float64(d.n)-k, k+1, 1-d.p)
+}
+
+// Quantile returns the smallest k such that CDF(k) > u.
+func (d *Binomial) Quantile(u float64) float64 {
+	return float64(binomialInv(u, d.n, d.p))
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Binomial) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns n p.
+func (d *Binomial) Mean() float64 {
+	return float64(d.n) * d.p
+}
+
+// Variance returns n p (1 - p).
+func (d *Binomial) Variance() float64 {
+	return float64(d.n) * d.p * (1 - d.p)
+}
+
+// StudentT is Student's t distribution with nu > 0 degrees of freedom.
+type StudentT struct {
+	nu float64
+}
+
+// NewStudentT returns Student's t distribution with nu > 0 degrees of
+// freedom. nu may be +Inf, which gives the standard normal distribution.
+func NewStudentT(nu float64) (*StudentT, error) {
+	if !(nu > 0) {
+		return nil, paramError("student t: need nu > 0")
+	}
+	return &StudentT{nu: nu}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *StudentT) CDF(x float64) float64 {
+	if math.IsInf(d.nu, 1) {
+		return StdNormalCDF(x)
+	}
+	tail := 0.5 * regIncBeta(d.nu/2, 0.5, d.nu/(d.nu+x*x))
+	if x > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// PDF returns the density at x.
+func (d *StudentT) PDF(x float64) float64 {
+	if math.IsInf(d.nu, 1) {
+		return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+	}
+	la, _ := math.Lgamma((d.nu + 1) / 2)
+	lb, _ := math.Lgamma(d.nu / 2)
+	return math.Exp(la-lb-(d.nu+1)/2*math.Log1p(x*x/d.nu)) / math.Sqrt(d.nu*math.Pi)
+}
+
+// Quantile returns the inverse of the distribution function at u,
+// computed numerically except for nu = 1 and nu = 2.
+func (d *StudentT) Quantile(u float64) float64 {
+	switch {
+	case math.IsInf(d.nu, 1):
+		return StdNormalInv(u)
+	case d.nu == 1:
+		return math.Tan(math.Pi * (u - 0.5))
+	case d.nu == 2:
+		a := 4 * u * (1 - u)
+		return (2*u - 1) * math.Sqrt(2/a)
+	case u == 0.5:
+		return 0
+	}
+	// solve in the lower tail, where the CDF keeps its relative precision
+	v := u
+	if u > 0.5 {
+		v = 1 - u
+	}
+	x := invertCDF(d.CDF, d.PDF, v, StdNormalInv(v), math.Inf(-1), 0)
+	if u > 0.5 {
+		return -x
+	}
+	return x
+}
+
+// Survival returns 1 - CDF(x), computed by symmetry as CDF(-x).
+func (d *StudentT) Survival(x float64) float64 {
+	return d.CDF(-x)
+}
+
+// InverseSurvival returns the inverse of the survival function at p,
+// -Quantile(p) by symmetry.
+func (d *StudentT) InverseSurvival(p float64) float64 {
+	return -d.Quantile(p)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *StudentT) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns 0 if nu > 1, and NaN otherwise.
+func (d *StudentT) Mean() float64 {
+	if d.nu > 1 {
+		return 0
+	}
+	return math.NaN()
+}
+
+// Variance returns nu/(nu - 2) if nu > 2, and +Inf otherwise.
+func (d *StudentT) Variance() float64 {
+	switch {
+	case math.IsInf(d.nu, 1):
+		return 1
+	case d.nu > 2:
+		return d.nu / (d.nu - 2)
+	}
+	return math.Inf(1)
+}
+
+// Beta is the beta distribution on (0, 1) with shape parameters a and b.
+type Beta struct {
+	a, b float64
+}
+
+// NewBeta returns the beta distribution with shapes a > 0 and b > 0.
+func NewBeta(a, b float64) (*Beta, error) {
+	if !(a > 0) || !(b > 0) || math.IsInf(a, 1) || math.IsInf(b, 1) {
+		return nil, paramError("beta: need finite a > 0 and b > 0")
+	}
+	return &Beta{a: a, b: b}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Beta) CDF(x float64) float64 {
+	return regIncBeta(d.a, d.b, x)
+}
+
+// PDF returns the density at x.
+func (d *Beta) PDF(x float64) float64 {
+	if x < 0 || x > 1 {
+		return 0
+	}
+	lab, _ := math.Lgamma(d.a + d.b)
+	la, _ := math.Lgamma(d.a)
+	lb, _ := math.Lgamma(d.b)
+	return math.Exp(lab - la - lb + (d.a-1)*math.Log(x) + (d.b-1)*math.Log1p(-x))
+}
+
+// Quantile returns the inverse of the distribution function at u,
+// computed numerically.
+func (d *Beta) Quantile(u float64) float64 {
+	if u <= 0 {
+		return 0
+	}
+	if u >= 1 {
+		return 1
+	}
+	x0 := d.Mean()
+	// near 0 and 1 the density dominates the behaviour of the CDF
+	if lo := math.Pow(u*d.a*betaFn(d.a, d.b), 1/d.a); lo < x0 {
+		x0 = lo
+	}
+	if hi := 1 - math.Pow((1-u)*d.b*betaFn(d.a, d.b), 1/d.b); hi > x0 && u > 0.5 {
+		x0 = hi
+	}
+	return invertCDF(d.CDF, d.PDF, u, x0, 0, 1)
+}
+
+// betaFn returns the beta function B(a, b).
+func betaFn(a, b float64) float64 {
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	return math.Exp(la + lb - lab)
+}
+
+// Sample returns a variate generated by (numerical) inversion from one
+// call to RandU01.
+func (d *Beta) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns a/(a + b).
+func (d *Beta) Mean() float64 {
+	return d.a / (d.a + d.b)
+}
+
+// Variance returns a b/((a + b)^2 (a + b + 1)).
+func (d *Beta) Variance() float64 {
+	s := d.a + d.b
+	return d.a * d.b / (s * s * (s + 1))
+}
@@ -254,3 +254,36 @@ func ExampleRngStream_WriteStateFull() {
 	//   Cg = { 12345,12345,12345,12345,12345,12345}
 
 }
+
+func ExampleUniformStream() {
+	// Reset seed to make test deterministic
+	rngstream.SetPackageSeed(initialSeed)
+
+	// A model written against UniformStream draws its variates by
+	// inversion, one replication per substream
+	service, _ := rngstream.NewExponential(2)
+	travel, _ := rngstream.NewWeibull(1.5, 1)
+	meanTime := func(s rngstream.UniformStream, n int) float64 {
+		sum := 0.0
+		for i := 0; i < n; i++ {
+			sum += rngstream.SampleInversion(service, s) + rngstream.SampleInversion(travel, s)
+			s.ResetNextSubstream()
+		}
+		return sum / float64(n)
+	}
+
+	// It runs unchanged on a Monte Carlo stream and on the points of a
+	// scrambled Sobol sequence
+	mc := rngstream.New("mc")
+	sobol, _ := rngstream.NewSobol(2)
+	sobol.SetRandomization(rngstream.New("scrambling"), rngstream.OwenScrambling)
+	qmc := rngstream.NewPointStream(sobol, nil)
+
+	fmt.Printf("exact %.4f\n", service.Mean()+travel.Mean())
+	fmt.Printf("MC    %.4f\n", meanTime(mc, 1024))
+	fmt.Printf("RQMC  %.4f\n", meanTime(qmc, 1024))
+	// Output:
+	// exact 1.4027
+	// MC    1.4299
+	// RQMC  1.4026
+}
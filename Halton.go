@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Halton generates the points of the Halton sequence in [0,1)^d, whose
+// coordinate j is the radical inverse of the point index in base b_j, the
+// j-th prime. With randomization, digit k of coordinate j is mapped
+// through a random permutation of {0, ..., b_j - 1}, independent for
+// every base and digit position, including the leading zeros of the
+// index up to the number of digits a float64 resolves. This keeps the
+// stratification of the sequence in every base and makes every point
+// uniformly distributed, so that estimators are unbiased.
+//
+// The permutations are derived from a 64-bit seed per dimension through a
+// hash function, as for Owen scrambling of Sobol sequences, and are built
+// on first use: leading zeros only need the image of 0, so a permutation
+// of digit position k is only stored once the index has k+1 digits in
+// base b_j. Memory thus grows with the number of points used, not with
+// the digit depth of every base.
+//
+// The seeds are drawn from a stream, from the beginning of its current
+// substream, and the reset methods mirror those of Sobol. Without
+// randomization, the first point is the origin.
+type Halton struct {
+	base  []int
+	depth []int       // digits permuted per base, b^depth >= 2^53
+	seed  []uint64    // per base; nil without randomization
+	perm  [][][]int32 // per base and digit position, built on first use
+	g     *RngStream
+	index uint64 // index of the next point
+}
+
+// NewHalton returns the Halton sequence of dimension dim >= 1, without
+// randomization.
+func NewHalton(dim int) (*Halton, error) {
+	if dim < 1 {
+		return nil, ErrDimension
+	}
+	h := &Halton{base: firstPrimes(dim), depth: make([]int, dim)}
+	for j, b := range h.base {
+		h.depth[j] = int(math.Ceil(53 * math.Ln2 / math.Log(float64(b))))
+	}
+	return h, nil
+}
+
+// firstPrimes returns the first n primes.
+func firstPrimes(n int) []int {
+	// the n-th prime is below n (ln n + ln ln n) for n >= 6
+	limit := 15
+	if n >= 6 {
+		x := float64(n)
+		limit = int(x*(math.Log(x)+math.Log(math.Log(x)))) + 1
+	}
+	composite := make([]bool, limit+1)
+	primes := make([]int, 0, n)
+	for i := 2; len(primes) < n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for k := i * i; k <= limit; k += i {
+			composite[k] = true
+		}
+	}
+	return primes
+}
+
+// Dim returns the dimension of the points.
+func (h *Halton) Dim() int {
+	return len(h.base)
+}
+
+// SetRandomization sets the stream of the randomization and draws the
+// seeds of the permutations from the beginning of its current substream,
+// with 2 calls to RandU01 per dimension; g == nil removes the
+// randomization. The sequence restarts at its first point.
+func (h *Halton) SetRandomization(g *RngStream) {
+	h.g = g
+	h.randomize()
+}
+
+func (h *Halton) randomize() {
+	h.seed, h.perm = nil, nil
+	if h.g != nil {
+		h.g.ResetStartSubstream()
+		h.seed = make([]uint64, len(h.base))
+		h.perm = make([][][]int32, len(h.base))
+		for j := range h.seed {
+			h.seed[j] = uint64(randomWord(h.g))<<32 | uint64(randomWord(h.g))
+			h.perm[j] = make([][]int32, h.depth[j])
+		}
+	}
+	h.index = 0
+}
+
+// Next stores the next point in dst, whose length must be at least Dim.
+func (h *Halton) Next(dst []float64) {
+	if len(dst) < len(h.base) {
+		panic(ErrDimension)
+	}
+	for j := range h.base {
+		dst[j] = h.coordinate(j, h.index)
+	}
+	h.index++
+}
+
+// coordinate returns the radical inverse of n in base b_j, with its first
+// depth_j digits, leading zeros included, permuted if h is randomized.
+func (h *Halton) coordinate(j int, n uint64) float64 {
+	b := h.base[j]
+	depth := 0
+	if h.seed != nil {
+		depth = h.depth[j]
+	}
+	base := uint64(b)
+	x, f := 0.0, 1/float64(b)
+	for k := 0; n > 0 || k < depth; k++ {
+		d := int(n % base)
+		if k < depth {
+			d = h.digit(j, k, d)
+		}
+		x += float64(d) * f
+		f /= float64(b)
+		n /= base
+	}
+	if x >= 1 {
+		// rounding of the last digits
+		x = math.Nextafter(1, 0)
+	}
+	return x
+}
+
+// digit returns the image of digit d at position k of coordinate j. The
+// permutation is a Fisher-Yates shuffle whose i-th swap is drawn from the
+// hash of (seed, k, i), so its image of 0, all that leading zeros need,
+// is known without building it.
+func (h *Halton) digit(j, k, d int) int {
+	b := h.base[j]
+	p := h.perm[j][k]
+	if p == nil {
+		if d == 0 {
+			return int(haltonHash(h.seed[j], k, 0) % uint64(b))
+		}
+		p = make([]int32, b)
+		for i := range p {
+			p[i] = int32(i)
+		}
+		for i := 0; i < b-1; i++ {
+			r := i + int(haltonHash(h.seed[j], k, i)%uint64(b-i))
+			p[i], p[r] = p[r], p[i]
+		}
+		h.perm[j][k] = p
+	}
+	return int(p[d])
+}
+
+// haltonHash returns the random word of swap i of the permutation of digit
+// position k for the given seed.
+func haltonHash(seed uint64, k, i int) uint64 {
+	return splitMix64(seed ^ splitMix64(uint64(k)<<32|uint64(i)))
+}
+
+// SkipTo moves to point n (counting from 0), so that the next call to
+// Next returns it.
+func (h *Halton) SkipTo(n uint64) {
+	h.index = n
+}
+
+// Index returns the index of the next point.
+func (h *Halton) Index() uint64 {
+	return h.index
+}
+
+// ResetStartStream restarts the sequence at its first point, with the
+// randomization drawn from the first substream of the stream.
+func (h *Halton) ResetStartStream() {
+	if h.g != nil {
+		h.g.ResetStartStream()
+	}
+	h.randomize()
+}
+
+// ResetStartSubstream restarts the sequence at its first point, with the
+// same randomization.
+func (h *Halton) ResetStartSubstream() {
+	h.SkipTo(0)
+}
+
+// ResetNextSubstream restarts the sequence at its first point, with new
+// permutations drawn from the next substream of the stream.
+func (h *Halton) ResetNextSubstream() {
+	if h.g != nil {
+		h.g.ResetNextSubstream()
+	}
+	h.randomize()
+}
@@ -0,0 +1,157 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// isStratified reports whether the b^m points x have exactly one point in
+// each interval [k b^-m, (k+1) b^-m) of coordinate j.
+func isStratified(x [][]float64, j, b, m int) bool {
+	n := int(math.Pow(float64(b), float64(m)))
+	seen := make([]bool, n)
+	for _, p := range x {
+		k := int(p[j] * float64(n))
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+	}
+	return len(x) == n
+}
+
+func TestHalton(t *testing.T) {
+	if p := firstPrimes(1000); p[0] != 2 || p[4] != 11 || p[999] != 7919 {
+		t.Errorf("primes %v ... %v", p[:5], p[999])
+	}
+
+	h, err := NewHalton(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{0, 0}, {0.5, 1.0 / 3}, {0.25, 2.0 / 3}, {0.75, 1.0 / 9}, {0.125, 4.0 / 9}}
+	p := make([]float64, 2)
+	for i, w := range want {
+		h.Next(p)
+		if math.Abs(p[0]-w[0]) > 1e-15 || math.Abs(p[1]-w[1]) > 1e-15 {
+			t.Errorf("point %d = %v, wanted %v", i, p, w)
+		}
+	}
+	h.SkipTo(3)
+	h.Next(p)
+	if p[0] != 0.75 {
+		t.Errorf("SkipTo(3) gives %v", p)
+	}
+	if _, err := NewHalton(0); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
+
+func TestHaltonRandomization(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("halton")
+	h, _ := NewHalton(4)
+	h.SetRandomization(g)
+
+	// the permutations keep the stratification in every base: 2^8, 3^5,
+	// 5^3 and 7^2 first points
+	for j, m := range []int{8, 5, 3, 2} {
+		b := h.base[j]
+		n := int(math.Pow(float64(b), float64(m)))
+		h.ResetStartSubstream()
+		x := newMatrix(n, 4)
+		for i := range x {
+			h.Next(x[i])
+			for _, v := range x[i] {
+				if !(v >= 0 && v < 1) {
+					t.Fatalf("coordinate %v out of [0,1)", v)
+				}
+			}
+		}
+		if !isStratified(x, j, b, m) {
+			t.Errorf("coordinate %d is not stratified in base %d", j, b)
+		}
+	}
+
+	// reset semantics as Sobol
+	y, z := make([]float64, 4), make([]float64, 4)
+	h.ResetStartSubstream()
+	h.Next(y)
+	h.ResetStartSubstream()
+	h.Next(z)
+	if y[0] != z[0] {
+		t.Errorf("ResetStartSubstream changed the randomization")
+	}
+	h.ResetNextSubstream()
+	h.Next(z)
+	if y[1] == z[1] && y[2] == z[2] && y[3] == z[3] {
+		t.Errorf("ResetNextSubstream kept the randomization")
+	}
+	h.ResetStartStream()
+	h.Next(z)
+	if y[3] != z[3] {
+		t.Errorf("ResetStartStream did not restore the randomization")
+	}
+
+	// leading zeros map through the permutations built later
+	h.ResetStartSubstream()
+	h.Next(y)
+	h.SkipTo(2 * 3 * 5 * 7 * 7)
+	h.Next(z)
+	h.ResetStartSubstream()
+	h.Next(z)
+	for j := range y {
+		if y[j] != z[j] {
+			t.Errorf("coordinate %d of the first point changed from %v to %v", j, y[j], z[j])
+		}
+	}
+
+	// in high dimension, the permutations of the leading zeros are never
+	// built
+	big, _ := NewHalton(1000)
+	big.SetRandomization(g)
+	x := make([]float64, 1000)
+	for i := 0; i < 100; i++ {
+		big.Next(x)
+	}
+	// only digit positions k with b^k <= 99 have nonzero digits
+	built, want := 0, 0
+	for j, b := range big.base {
+		for k, p := range big.perm[j] {
+			if p != nil {
+				built++
+			}
+			if math.Pow(float64(b), float64(k)) <= 99 {
+				want++
+			}
+		}
+	}
+	if built != want {
+		t.Errorf("%d permutations built for 100 points, wanted %d", built, want)
+	}
+
+	// unbiased: the product of the coordinates has mean 1/16
+	var sum, sq float64
+	const reps = 100
+	for r := 0; r < reps; r++ {
+		h.ResetNextSubstream()
+		est := 0.0
+		for i := 0; i < 500; i++ {
+			h.Next(y)
+			est += y[0] * y[1] * y[2] * y[3]
+		}
+		est /= 500
+		sum += est
+		sq += est * est
+	}
+	m := sum / reps
+	se := math.Sqrt((sq/reps - m*m) / (reps - 1))
+	if math.Abs(m-1.0/16) > 4*se {
+		t.Errorf("mean %v +- %v, wanted 1/16", m, se)
+	}
+	// far below the Monte Carlo standard error of 500 points
+	mc := math.Sqrt((1.0/81 - 1.0/256) / 500)
+	if se > mc/5 {
+		t.Errorf("standard error %v, Monte Carlo gives %v", se, mc)
+	}
+}
@@ -0,0 +1,306 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// AlphaStable is the alpha-stable distribution S(alpha, beta, gamma, delta)
+// with stability index 0 < alpha <= 2, skewness -1 <= beta <= 1, scale
+// gamma > 0 and location delta, in the parameterization of Samorodnitsky
+// and Taqqu (Nolan's S1). For alpha = 2 it is the normal distribution with
+// mean delta and variance 2 gamma^2; for alpha = 1 and beta = 0 the Cauchy
+// distribution; for alpha = 1/2 and beta = 1 the Lévy distribution.
+//
+// Its distribution function has no closed form, so AlphaStable is a
+// Sampler but not a Distribution.
+type AlphaStable struct {
+	alpha, beta, gamma, delta float64
+	b, s                      float64 // constants for alpha != 1
+}
+
+// NewAlphaStable returns the alpha-stable distribution with the given
+// parameters.
+func NewAlphaStable(alpha, beta, gamma, delta float64) (*AlphaStable, error) {
+	if !(alpha > 0 && alpha <= 2) {
+		return nil, paramError("alpha-stable: need 0 < alpha <= 2")
+	}
+	if !(beta >= -1 && beta <= 1) {
+		return nil, paramError("alpha-stable: need -1 <= beta <= 1")
+	}
+	if !(gamma > 0) || math.IsInf(gamma, 1) || math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return nil, paramError("alpha-stable: need finite gamma > 0 and delta")
+	}
+	d := &AlphaStable{alpha: alpha, beta: beta, gamma: gamma, delta: delta}
+	if alpha != 1 {
+		zeta := beta * math.Tan(math.Pi*alpha/2)
+		d.b = math.Atan(zeta) / alpha
+		d.s = math.Pow(1+zeta*zeta, 1/(2*alpha))
+	}
+	return d, nil
+}
+
+// Sample returns a variate generated with the method of Chambers, Mallows
+// and Stuck (1976), in the form given by Weron (1996). It makes exactly two
+// calls to RandU01: the first gives the uniform angle, the second the
+// exponential variate, by inversion.
+func (d *AlphaStable) Sample(g *RngStream) float64 {
+	v := math.Pi * (g.RandU01() - 0.5)
+	w := -math.Log(g.RandU01())
+	if d.alpha == 1 {
+		// the alpha = 1 case has its own formula and a log(gamma) shift
+		// in this parameterization
+		h := math.Pi/2 + d.beta*v
+		x := 2 / math.Pi * (h*math.Tan(v) - d.beta*math.Log(math.Pi/2*w*math.Cos(v)/h))
+		return d.gamma*x + 2/math.Pi*d.beta*d.gamma*math.Log(d.gamma) + d.delta
+	}
+	a := d.alpha
+	x := d.s * math.Sin(a*(v+d.b)) / math.Pow(math.Cos(v), 1/a) *
+		math.Pow(math.Cos(v-a*(v+d.b))/w, (1-a)/a)
+	return d.gamma*x + d.delta
+}
+
+// Mean returns delta if alpha > 1, and NaN otherwise, as the mean does
+// not exist.
+func (d *AlphaStable) Mean() float64 {
+	if d.alpha > 1 {
+		return d.delta
+	}
+	return math.NaN()
+}
+
+// Variance returns 2 gamma^2 if alpha = 2, and +Inf otherwise.
+func (d *AlphaStable) Variance() float64 {
+	if d.alpha == 2 {
+		return 2 * d.gamma * d.gamma
+	}
+	return math.Inf(1)
+}
+
+// Levy is the Lévy distribution with location mu and scale c, whose
+// distribution function is erfc(sqrt(c / (2 (x - mu)))) for x > mu.
+type Levy struct {
+	mu, c float64
+}
+
+// NewLevy returns the Lévy distribution with location mu and scale c > 0.
+func NewLevy(mu, c float64) (*Levy, error) {
+	if !(c > 0) || math.IsInf(c, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) {
+		return nil, paramError("levy: need finite mu and c > 0")
+	}
+	return &Levy{mu: mu, c: c}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Levy) CDF(x float64) float64 {
+	if x <= d.mu {
+		return 0
+	}
+	return math.Erfc(math.Sqrt(d.c / (2 * (x - d.mu))))
+}
+
+// PDF returns the density at x.
+func (d *Levy) PDF(x float64) float64 {
+	if x <= d.mu {
+		return 0
+	}
+	y := x - d.mu
+	return math.Sqrt(d.c/(2*math.Pi)) * math.Exp(-d.c/(2*y)) / (y * math.Sqrt(y))
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *Levy) Quantile(u float64) float64 {
+	z := StdNormalInv(u / 2)
+	return d.mu + d.c/(z*z)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Levy) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns +Inf.
+func (d *Levy) Mean() float64 {
+	return math.Inf(1)
+}
+
+// Variance returns +Inf.
+func (d *Levy) Variance() float64 {
+	return math.Inf(1)
+}
+
+// GeneralizedPareto is the generalized Pareto distribution with location
+// mu, scale sigma and shape xi, whose distribution function is
+// 1 - (1 + xi z)^(-1/xi), z = (x - mu)/sigma, or 1 - exp(-z) if xi = 0.
+// The support is z >= 0, bounded above by -1/xi when xi < 0.
+type GeneralizedPareto struct {
+	mu, sigma, xi float64
+}
+
+// NewGeneralizedPareto returns the generalized Pareto distribution with
+// location mu, scale sigma > 0 and shape xi.
+func NewGeneralizedPareto(mu, sigma, xi float64) (*GeneralizedPareto, error) {
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) ||
+		math.IsNaN(xi) || math.IsInf(xi, 0) {
+		return nil, paramError("generalized Pareto: need finite mu, xi and sigma > 0")
+	}
+	return &GeneralizedPareto{mu: mu, sigma: sigma, xi: xi}, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *GeneralizedPareto) CDF(x float64) float64 {
+	z := (x - d.mu) / d.sigma
+	if z <= 0 {
+		return 0
+	}
+	if d.xi < 0 && z >= -1/d.xi {
+		return 1
+	}
+	return -math.Expm1(-z * log1pRatio(d.xi*z))
+}
+
+// PDF returns the density at x.
+func (d *GeneralizedPareto) PDF(x float64) float64 {
+	z := (x - d.mu) / d.sigma
+	if z < 0 || (d.xi < 0 && z > -1/d.xi) {
+		return 0
+	}
+	return math.Exp(-(1+d.xi)*z*log1pRatio(d.xi*z)) / d.sigma
+}
+
+// Quantile returns the inverse of the distribution function at u. At
+// u = 1 it is the upper end of the support, mu - sigma/xi if xi < 0 and
+// +Inf otherwise.
+func (d *GeneralizedPareto) Quantile(u float64) float64 {
+	if u >= 1 {
+		if d.xi < 0 {
+			return d.mu - d.sigma/d.xi
+		}
+		return math.Inf(1)
+	}
+	l := -math.Log1p(-u)
+	return d.mu + d.sigma*l*expm1Ratio(d.xi*l)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *GeneralizedPareto) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns mu + sigma/(1 - xi) if xi < 1, and +Inf otherwise.
+func (d *GeneralizedPareto) Mean() float64 {
+	if d.xi >= 1 {
+		return math.Inf(1)
+	}
+	return d.mu + d.sigma/(1-d.xi)
+}
+
+// Variance returns sigma^2/((1 - xi)^2 (1 - 2 xi)) if xi < 1/2, and +Inf
+// otherwise.
+func (d *GeneralizedPareto) Variance() float64 {
+	if d.xi >= 0.5 {
+		return math.Inf(1)
+	}
+	return d.sigma * d.sigma / ((1 - d.xi) * (1 - d.xi) * (1 - 2*d.xi))
+}
+
+// GEV is the generalized extreme value distribution with location mu,
+// scale sigma and shape xi, whose distribution function is
+// exp(-(1 + xi z)^(-1/xi)), z = (x - mu)/sigma, or exp(-exp(-z)) (Gumbel)
+// if xi = 0. It is the Fréchet family for xi > 0 and the reversed Weibull
+// family for xi < 0.
+type GEV struct {
+	mu, sigma, xi float64
+}
+
+// NewGEV returns the generalized extreme value distribution with location
+// mu, scale sigma > 0 and shape xi.
+func NewGEV(mu, sigma, xi float64) (*GEV, error) {
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) ||
+		math.IsNaN(xi) || math.IsInf(xi, 0) {
+		return nil, paramError("GEV: need finite mu, xi and sigma > 0")
+	}
+	return &GEV{mu: mu, sigma: sigma, xi: xi}, nil
+}
+
+// tz returns (1 + xi z)^(-1/xi), or exp(-z) if xi = 0; ok is false outside
+// the support.
+func (d *GEV) tz(x float64) (t float64, ok bool) {
+	z := (x - d.mu) / d.sigma
+	if 1+d.xi*z <= 0 {
+		return 0, false
+	}
+	return math.Exp(-z * log1pRatio(d.xi*z)), true
+}
+
+// CDF returns the distribution function at x.
+func (d *GEV) CDF(x float64) float64 {
+	t, ok := d.tz(x)
+	if !ok {
+		if d.xi > 0 {
+			return 0 // below the lower end point
+		}
+		return 1
+	}
+	return math.Exp(-t)
+}
+
+// PDF returns the density at x.
+func (d *GEV) PDF(x float64) float64 {
+	t, ok := d.tz(x)
+	if !ok {
+		return 0
+	}
+	return math.Pow(t, d.xi+1) * math.Exp(-t) / d.sigma
+}
+
+// Quantile returns the inverse of the distribution function at u.
+func (d *GEV) Quantile(u float64) float64 {
+	l := -math.Log(-math.Log(u))
+	return d.mu + d.sigma*l*expm1Ratio(d.xi*l)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *GEV) Sample(g *RngStream) float64 {
+	return SampleInversion(d, g)
+}
+
+// Mean returns the mean if xi < 1, and +Inf otherwise.
+func (d *GEV) Mean() float64 {
+	x := d.xi
+	switch {
+	case x >= 1:
+		return math.Inf(1)
+	case math.Abs(x) < 1e-3:
+		// (Gamma(1-xi) - 1)/xi, from the series of log Gamma(1-xi)
+		l := x * (eulerGamma + x*(zeta2/2+x*(zeta3/3+x*zeta4/4)))
+		return d.mu + d.sigma*(eulerGamma+x*(zeta2/2+x*(zeta3/3+x*zeta4/4)))*expm1Ratio(l)
+	}
+	return d.mu + d.sigma*(math.Gamma(1-x)-1)/x
+}
+
+// Variance returns the variance if xi < 1/2, and +Inf otherwise.
+func (d *GEV) Variance() float64 {
+	x := d.xi
+	switch {
+	case x >= 0.5:
+		return math.Inf(1)
+	case math.Abs(x) < 1e-3:
+		// (Gamma(1-2xi) - Gamma(1-xi)^2)/xi^2 = Gamma(1-xi)^2 expm1(D)/xi^2,
+		// with D = log Gamma(1-2xi) - 2 log Gamma(1-xi) from its series
+		q := zeta2 + x*(2*zeta3+x*(3.5*zeta4+x*6*zeta5))
+		g1 := math.Gamma(1 - x)
+		return d.sigma * d.sigma * g1 * g1 * q * expm1Ratio(q*x*x)
+	}
+	g1 := math.Gamma(1 - x)
+	g2 := math.Gamma(1 - 2*x)
+	return d.sigma * d.sigma * (g2 - g1*g1) / (x * x)
+}
+
+// Euler-Mascheroni constant and values of the Riemann zeta function.
+const (
+	eulerGamma = 0.57721566490153286061
+	zeta2      = 1.6449340668482264365
+	zeta3      = 1.2020569031595942854
+	zeta4      = 1.0823232337111381915
+	zeta5      = 1.0369277551433699263
+)
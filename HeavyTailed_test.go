@@ -0,0 +1,116 @@
+package rngstream
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+var (
+	_ Continuous = (*Levy)(nil)
+	_ Continuous = (*GeneralizedPareto)(nil)
+	_ Continuous = (*GEV)(nil)
+)
+
+// quantiles returns the empirical p-quantiles of n variates from s.
+func quantiles(s func() float64, n int, p []float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = s()
+	}
+	sort.Float64s(x)
+	q := make([]float64, len(p))
+	for i, pi := range p {
+		q[i] = x[int(pi*float64(n))]
+	}
+	return q
+}
+
+func TestGPDAndGEV(t *testing.T) {
+	var ds []Distribution
+	for _, xi := range []float64{-0.3, 0, 1e-10, 0.2} {
+		gpd, err := NewGeneralizedPareto(1, 2, xi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gev, err := NewGEV(-1, 0.5, xi)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ds = append(ds, gpd, gev)
+	}
+	for _, d := range ds {
+		m, v := quantileMoments(d.Quantile)
+		if math.Abs(m-d.Mean()) > 1e-5*(1+math.Abs(m)) || math.Abs(v-d.Variance()) > 1e-4*(1+v) {
+			t.Errorf("%+v: moments (%v, %v), quadrature gives (%v, %v)", d, d.Mean(), d.Variance(), m, v)
+		}
+		for _, u := range []float64{1e-8, 0.2, 0.5, 0.9, 1 - 1e-8} {
+			if got := d.CDF(d.Quantile(u)); math.Abs(got-u) > 1e-9 {
+				t.Errorf("%+v: CDF(Quantile(%v)) = %v", d, u, got)
+			}
+		}
+	}
+	// the upper end of the support
+	bounded, _ := NewGeneralizedPareto(1, 2, -0.25)
+	unbounded, _ := NewGeneralizedPareto(1, 2, 0.2)
+	if q := bounded.Quantile(1); q != 9 {
+		t.Errorf("GPD with xi < 0: Quantile(1) = %v, wanted 9", q)
+	}
+	if q := unbounded.Quantile(1); !math.IsInf(q, 1) {
+		t.Errorf("GPD with xi > 0: Quantile(1) = %v, wanted +Inf", q)
+	}
+	if _, err := NewGEV(0, 0, 0.1); err == nil {
+		t.Errorf("expected error for zero scale")
+	}
+}
+
+func TestAlphaStable(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("stable")
+	p := []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+	const n = 40000
+
+	check := func(name string, got []float64, want func(p float64) float64) {
+		for i, pi := range p {
+			w := want(pi)
+			if math.Abs(got[i]-w) > 0.05*(1+math.Abs(w)) {
+				t.Errorf("%s: %v-quantile %v, wanted %v", name, pi, got[i], w)
+			}
+		}
+	}
+
+	// alpha = 2 is N(delta, 2 gamma^2)
+	s2, _ := NewAlphaStable(2, 0.7, 1.5, 3)
+	check("alpha=2", quantiles(func() float64 { return s2.Sample(g) }, n, p),
+		func(p float64) float64 { return 3 + 1.5*math.Sqrt2*StdNormalInv(p) })
+
+	// alpha = 1, beta = 0 is Cauchy(delta, gamma)
+	s1, _ := NewAlphaStable(1, 0, 2, -1)
+	check("cauchy", quantiles(func() float64 { return s1.Sample(g) }, n, p),
+		func(p float64) float64 { return -1 + 2*math.Tan(math.Pi*(p-0.5)) })
+
+	// alpha = 1/2, beta = 1 is Levy(delta, gamma)
+	sl, _ := NewAlphaStable(0.5, 1, 0.7, 2)
+	levy, _ := NewLevy(2, 0.7)
+	check("levy", quantiles(func() float64 { return sl.Sample(g) }, n, p), levy.Quantile)
+
+	// alpha = 1, beta != 0: X1 + X2 has the law of 2 X + (4/pi) beta gamma log 2
+	sk, _ := NewAlphaStable(1, 0.6, 1.3, 0.4)
+	sum := quantiles(func() float64 { return sk.Sample(g) + sk.Sample(g) }, n, p)
+	single := quantiles(func() float64 { return sk.Sample(g) }, n, p)
+	shift := 4 / math.Pi * 0.6 * 1.3 * math.Log(2)
+	check("alpha=1 skewed", sum, func(pi float64) float64 {
+		for i := range p {
+			if p[i] == pi {
+				return 2*single[i] + shift
+			}
+		}
+		return math.NaN()
+	})
+
+	for _, bad := range [][4]float64{{0, 0, 1, 0}, {2.1, 0, 1, 0}, {1, 1.5, 1, 0}, {1, 0, -1, 0}} {
+		if _, err := NewAlphaStable(bad[0], bad[1], bad[2], bad[3]); err == nil {
+			t.Errorf("expected error for parameters %v", bad)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Tilted is the exponential tilting of a distribution F by theta: the
+// distribution with density (or mass) exp(theta x - kappa(theta)) f(x),
+// where kappa is the cumulant generating function of F. Sampling from it
+// instead of F is importance sampling, and the likelihood ratio of F with
+// respect to the tilted distribution at x is exp(kappa(theta) - theta x).
+//
+// Tilted distributions of the common families stay in the family, and
+// Sample and SampleLR draw from the tilted member with its own Sample
+// method, with the same calls to RandU01.
+type Tilted struct {
+	Distribution // the tilted distribution
+	theta        float64
+	kappa        float64 // cumulant generating function of F at theta
+}
+
+// TiltNormal returns the normal distribution with mean mu and standard
+// deviation sigma > 0 tilted by theta, the normal with mean
+// mu + theta sigma^2.
+func TiltNormal(mu, sigma, theta float64) (*Tilted, error) {
+	d, err := NewNormal(mu+theta*sigma*sigma, sigma)
+	if err != nil {
+		return nil, err
+	}
+	return &Tilted{Distribution: d, theta: theta, kappa: theta*mu + theta*theta*sigma*sigma/2}, nil
+}
+
+// TiltExponential returns the exponential distribution with the given
+// rate tilted by theta < rate, the exponential with rate rate - theta.
+func TiltExponential(rate, theta float64) (*Tilted, error) {
+	if !(theta < rate) {
+		return nil, paramError("tilted exponential: need theta < rate")
+	}
+	d, err := NewExponential(rate - theta)
+	if err != nil {
+		return nil, err
+	}
+	return &Tilted{Distribution: d, theta: theta, kappa: -math.Log1p(-theta / rate)}, nil
+}
+
+// TiltGamma returns the gamma distribution with the given shape and scale
+// tilted by theta < 1/scale, the gamma with scale scale/(1 - theta scale).
+func TiltGamma(shape, scale, theta float64) (*Tilted, error) {
+	if !(theta*scale < 1) {
+		return nil, paramError("tilted gamma: need theta < 1/scale")
+	}
+	d, err := NewGamma(shape, scale/(1-theta*scale))
+	if err != nil {
+		return nil, err
+	}
+	return &Tilted{Distribution: d, theta: theta, kappa: -shape * math.Log1p(-theta*scale)}, nil
+}
+
+// TiltBinomial returns the binomial distribution with n trials and
+// success probability 0 < p < 1 tilted by theta, the binomial with
+// success probability p e^theta / (1 - p + p e^theta).
+func TiltBinomial(n int, p, theta float64) (*Tilted, error) {
+	if !(p > 0 && p < 1) {
+		return nil, paramError("tilted binomial: need 0 < p < 1")
+	}
+	// log(1 - p + p e^theta), kept accurate for theta of either sign
+	m := math.Log1p(p * math.Expm1(theta))
+	d, err := NewBinomial(n, math.Exp(math.Log(p)+theta-m))
+	if err != nil {
+		return nil, err
+	}
+	return &Tilted{Distribution: d, theta: theta, kappa: float64(n) * m}, nil
+}
+
+// Theta returns the tilting parameter.
+func (t *Tilted) Theta() float64 {
+	return t.theta
+}
+
+// LogLikelihoodRatio returns kappa(theta) - theta x, the logarithm of the
+// likelihood ratio of the original distribution with respect to the
+// tilted one at x.
+func (t *Tilted) LogLikelihoodRatio(x float64) float64 {
+	return t.kappa - t.theta*x
+}
+
+// LikelihoodRatio returns the likelihood ratio of the original
+// distribution with respect to the tilted one at x.
+func (t *Tilted) LikelihoodRatio(x float64) float64 {
+	return math.Exp(t.LogLikelihoodRatio(x))
+}
+
+// SampleLR returns a variate x of the tilted distribution and the
+// likelihood ratio at x, the weight that makes estimates unbiased for the
+// original distribution.
+func (t *Tilted) SampleLR(g *RngStream) (x, lr float64) {
+	x = t.Sample(g)
+	return x, t.LikelihoodRatio(x)
+}
+
+// ChangeOfMeasure accumulates the likelihood ratio of a sample path whose
+// variates are drawn from tilted distributions, as the product of their
+// likelihood ratios, kept in logarithms so that long paths do not
+// underflow. Variates drawn from the original distributions, through
+// SampleOriginal or directly, contribute a ratio of 1.
+type ChangeOfMeasure struct {
+	logLR float64
+}
+
+// Sample returns a variate of t and multiplies the likelihood ratio of
+// the path by its likelihood ratio.
+func (c *ChangeOfMeasure) Sample(t *Tilted, g *RngStream) float64 {
+	x := t.Sample(g)
+	c.logLR += t.LogLikelihoodRatio(x)
+	return x
+}
+
+// SampleOriginal returns a variate of d, leaving the likelihood ratio of
+// the path unchanged.
+func (c *ChangeOfMeasure) SampleOriginal(d Sampler, g *RngStream) float64 {
+	return d.Sample(g)
+}
+
+// LikelihoodRatio returns the likelihood ratio of the path so far.
+func (c *ChangeOfMeasure) LikelihoodRatio() float64 {
+	return math.Exp(c.logLR)
+}
+
+// LogLikelihoodRatio returns the logarithm of the likelihood ratio of the
+// path so far.
+func (c *ChangeOfMeasure) LogLikelihoodRatio() float64 {
+	return c.logLR
+}
+
+// Reset starts a new path, with likelihood ratio 1.
+func (c *ChangeOfMeasure) Reset() {
+	c.logLR = 0
+}
+
+// WeightedEstimator accumulates the outputs y_i of independent
+// importance-sampling replications with their likelihood ratios w_i, and
+// estimates the mean under the original measure by (1/n) sum w_i y_i,
+// which is unbiased. The products w_i y_i are accumulated with Welford's
+// updates.
+type WeightedEstimator struct {
+	n      int
+	mean   float64 // of w y
+	m2     float64
+	sumW   float64
+	sumW2  float64
+	sumWY  float64
+	sumW2Y float64 // sum of w^2 y, for the self-normalized variance
+	sumWY2 float64 // sum of w^2 y^2
+}
+
+// Add records the output y of one replication with likelihood ratio w.
+func (e *WeightedEstimator) Add(y, w float64) {
+	if !(w >= 0) {
+		panic(paramError("weighted estimator: need weights >= 0"))
+	}
+	e.n++
+	v := w * y
+	d := v - e.mean
+	e.mean += d / float64(e.n)
+	e.m2 += d * (v - e.mean)
+	e.sumW += w
+	e.sumW2 += w * w
+	e.sumWY += v
+	e.sumW2Y += w * v
+	e.sumWY2 += v * v
+}
+
+// Count returns the number of replications recorded.
+func (e *WeightedEstimator) Count() int {
+	return e.n
+}
+
+// Mean returns the importance-sampling estimate (1/n) sum w_i y_i.
+func (e *WeightedEstimator) Mean() float64 {
+	return e.mean
+}
+
+// Variance returns the sample variance of the weighted outputs w_i y_i,
+// or NaN with fewer than two replications.
+func (e *WeightedEstimator) Variance() float64 {
+	if e.n < 2 {
+		return math.NaN()
+	}
+	return e.m2 / float64(e.n-1)
+}
+
+// StdError returns the standard error of Mean.
+func (e *WeightedEstimator) StdError() float64 {
+	return math.Sqrt(e.Variance() / float64(e.n))
+}
+
+// ConfidenceInterval returns the confidence interval of Mean with the
+// given level, from the Student t distribution with n-1 degrees of
+// freedom.
+func (e *WeightedEstimator) ConfidenceInterval(level float64) (low, high float64) {
+	h := tHalfWidth(e.StdError(), float64(e.n-1), level)
+	return e.mean - h, e.mean + h
+}
+
+// RelativeError returns StdError / Mean, the usual measure of the
+// efficiency of a rare-event estimator.
+func (e *WeightedEstimator) RelativeError() float64 {
+	return e.StdError() / e.mean
+}
+
+// EffectiveSampleSize returns (sum w_i)^2 / sum w_i^2, the number of
+// unweighted replications worth as much as the weighted ones for the
+// self-normalized estimator. It is n for equal weights, and much smaller
+// when a few weights dominate, a sign of a poor change of measure.
+func (e *WeightedEstimator) EffectiveSampleSize() float64 {
+	return e.sumW * e.sumW / e.sumW2
+}
+
+// SelfNormalizedMean returns sum w_i y_i / sum w_i, the estimator for
+// likelihood ratios known up to a constant factor, which is biased but
+// consistent, and its delta-method standard error.
+func (e *WeightedEstimator) SelfNormalizedMean() (mean, stdErr float64) {
+	mean = e.sumWY / e.sumW
+	// sum w_i^2 (y_i - mean)^2 / (sum w_i)^2
+	v := (e.sumWY2 - 2*mean*e.sumW2Y + mean*mean*e.sumW2) / (e.sumW * e.sumW)
+	return mean, math.Sqrt(math.Max(v, 0))
+}
@@ -0,0 +1,116 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTilted(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("tilted")
+	tiltN, _ := TiltNormal(1, 2, 0.5)
+	tiltE, _ := TiltExponential(2, 1.5)
+	tiltG, _ := TiltGamma(3, 0.5, -1)
+	tiltB, _ := TiltBinomial(20, 0.1, 1.2)
+	if m := tiltN.Mean(); math.Abs(m-3) > 1e-12 {
+		t.Errorf("tilted normal mean %v, wanted 3", m)
+	}
+	if m := tiltE.Mean(); math.Abs(m-2) > 1e-12 {
+		t.Errorf("tilted exponential mean %v, wanted 2", m)
+	}
+	// under the tilted measure, the likelihood ratio has mean 1 and
+	// recovers the original mean
+	for _, c := range []struct {
+		tilt *Tilted
+		mean float64
+	}{{tiltN, 1}, {tiltE, 0.5}, {tiltG, 1.5}, {tiltB, 2}} {
+		var lr, x WeightedEstimator
+		for i := 0; i < 100000; i++ {
+			v, w := c.tilt.SampleLR(g)
+			lr.Add(1, w)
+			x.Add(v, w)
+		}
+		if math.Abs(lr.Mean()-1) > 4*lr.StdError() {
+			t.Errorf("%T: likelihood ratio mean %v +- %v, wanted 1", c.tilt.Distribution, lr.Mean(), lr.StdError())
+		}
+		if math.Abs(x.Mean()-c.mean) > 4*x.StdError() {
+			t.Errorf("%T: mean %v +- %v, wanted %v", c.tilt.Distribution, x.Mean(), x.StdError(), c.mean)
+		}
+	}
+	if _, err := TiltExponential(1, 1); err == nil {
+		t.Errorf("expected error for theta >= rate")
+	}
+	if _, err := TiltGamma(2, 0.5, 2); err == nil {
+		t.Errorf("expected error for theta >= 1/scale")
+	}
+}
+
+func TestWeightedEstimator(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("is")
+
+	// P(Z > 5) = 2.8665157e-7, with the normal tilted to mean 5
+	tilt, _ := TiltNormal(0, 1, 5)
+	var e WeightedEstimator
+	for i := 0; i < 10000; i++ {
+		z, w := tilt.SampleLR(g)
+		y := 0.0
+		if z > 5 {
+			y = 1
+		}
+		e.Add(y, w)
+	}
+	exact := 2.8665157187919333e-7
+	if math.Abs(e.Mean()-exact) > 4*e.StdError() || e.RelativeError() > 0.05 {
+		t.Errorf("estimate %v +- %v, wanted %v", e.Mean(), e.StdError(), exact)
+	}
+	lo, hi := e.ConfidenceInterval(0.95)
+	if !(lo < e.Mean() && e.Mean() < hi) {
+		t.Errorf("interval [%v, %v] around %v", lo, hi, e.Mean())
+	}
+
+	// P(S > 30) for S the sum of 10 exponentials of rate 1, drawn with
+	// rate 1/3 so that S has mean 30: the path likelihood ratio is the
+	// product of the ratios of its variates
+	exact = 0.0
+	term := 1.0
+	for k := 0; k < 10; k++ {
+		exact += term
+		term *= 30 / float64(k+1)
+	}
+	exact *= math.Exp(-30)
+	tiltE, _ := TiltExponential(1, 2.0/3)
+	var c ChangeOfMeasure
+	var sum WeightedEstimator
+	for i := 0; i < 20000; i++ {
+		c.Reset()
+		s := 0.0
+		for k := 0; k < 10; k++ {
+			s += c.Sample(tiltE, g)
+		}
+		y := 0.0
+		if s > 30 {
+			y = 1
+		}
+		sum.Add(y, c.LikelihoodRatio())
+	}
+	if math.Abs(sum.Mean()-exact) > 4*sum.StdError() || sum.RelativeError() > 0.05 {
+		t.Errorf("estimate %v +- %v, wanted %v", sum.Mean(), sum.StdError(), exact)
+	}
+
+	// equal weights: the effective sample size is n and the
+	// self-normalized estimator is the plain mean
+	var eq WeightedEstimator
+	for _, y := range []float64{1, 2, 3, 4} {
+		eq.Add(y, 0.5)
+	}
+	if eq.EffectiveSampleSize() != 4 {
+		t.Errorf("effective sample size %v, wanted 4", eq.EffectiveSampleSize())
+	}
+	if m, se := eq.SelfNormalizedMean(); m != 2.5 || math.Abs(se-math.Sqrt(5.0/4/4)) > 1e-12 {
+		t.Errorf("self-normalized mean %v +- %v, wanted 2.5 +- %v", m, se, math.Sqrt(5.0/16))
+	}
+	if eq.Mean() != 1.25 {
+		t.Errorf("mean %v, wanted 1.25", eq.Mean())
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Integrator estimates the integral of a function over [0,1]^d from m
+// independent replications of an n-point estimator: the mean of f over n
+// uniform points for Monte Carlo, or over the n first points of one
+// randomization of a point set for randomized quasi-Monte Carlo. The
+// confidence interval comes from the Student t distribution with m-1
+// degrees of freedom over the replication means.
+//
+// Replication r draws from substream r: the Monte Carlo stream, or the
+// randomization stream of the point set, is reset to the start of its
+// current substream for the first replication and moved to its next
+// substream for each of the others. Integrate thus leaves it in the last
+// substream used, and can be called again after ResetNextSubstream for
+// independent results. The point set must be randomized, or all
+// replications are identical.
+//
+// Replications continue beyond the minimum number until the half-width of
+// the confidence interval meets the target precision, if one is set, or
+// the maximum number is reached. Such sequential stopping slightly lowers
+// the coverage of the interval when few replications suffice.
+type Integrator struct {
+	dim    int
+	n      int
+	g      *RngStream // Monte Carlo
+	p      PointSet   // quasi-Monte Carlo
+	level  float64
+	absTol float64
+	relTol float64
+	minRep int
+	maxRep int
+}
+
+// IntegrationResult holds the outcome of Integrator.Integrate.
+type IntegrationResult struct {
+	Estimates   []float64 // per replication
+	Mean        float64   // estimate of the integral
+	StdError    float64   // standard error of Mean
+	Low, High   float64   // confidence interval
+	Level       float64   // confidence level of the interval
+	Evaluations int       // evaluations of f
+	Converged   bool      // whether the target precision was reached
+}
+
+// NewMCIntegrator returns a Monte Carlo integrator over [0,1]^dim, whose
+// replications average f over n >= 1 points drawn from g, with dim calls
+// to RandU01 per point.
+func NewMCIntegrator(g *RngStream, dim, n int) (*Integrator, error) {
+	if dim < 1 {
+		return nil, ErrDimension
+	}
+	if n < 1 {
+		return nil, paramError("integrator: need n >= 1 points per replication")
+	}
+	return newIntegrator(&Integrator{dim: dim, n: n, g: g}), nil
+}
+
+// NewQMCIntegrator returns a randomized quasi-Monte Carlo integrator over
+// [0,1]^d, d = p.Dim(), whose replications average f over the first
+// n >= 1 points of independent randomizations of p. For Sobol sequences,
+// n should be a power of two; for lattice rules, the number of points of
+// the rule.
+func NewQMCIntegrator(p PointSet, n int) (*Integrator, error) {
+	if n < 1 {
+		return nil, paramError("integrator: need n >= 1 points per replication")
+	}
+	return newIntegrator(&Integrator{dim: p.Dim(), n: n, p: p}), nil
+}
+
+func newIntegrator(it *Integrator) *Integrator {
+	it.level = 0.95
+	it.minRep, it.maxRep = 10, 1000
+	return it
+}
+
+// SetLevel sets the confidence level, 0 < level < 1, 0.95 by default.
+func (it *Integrator) SetLevel(level float64) {
+	if !(level > 0 && level < 1) {
+		panic(paramError("integrator: need 0 < level < 1"))
+	}
+	it.level = level
+}
+
+// SetTolerance sets the target precision: replications stop once the
+// half-width of the confidence interval is at most max(abs, rel |Mean|).
+// Zero values, the default, disable the target, and exactly the minimum
+// number of replications is run.
+func (it *Integrator) SetTolerance(abs, rel float64) {
+	if !(abs >= 0 && rel >= 0) {
+		panic(paramError("integrator: need tolerances >= 0"))
+	}
+	it.absTol, it.relTol = abs, rel
+}
+
+// SetReplications sets the minimum lo and maximum hi numbers of
+// replications, 2 <= lo <= hi, 10 and 1000 by default.
+func (it *Integrator) SetReplications(lo, hi int) {
+	if lo < 2 || hi < lo {
+		panic(paramError("integrator: need 2 <= min <= max replications"))
+	}
+	it.minRep, it.maxRep = lo, hi
+}
+
+// Integrate estimates the integral of f, which is called with points of
+// [0,1]^d in a slice it must not retain.
+func (it *Integrator) Integrate(f func(x []float64) float64) *IntegrationResult {
+	res := &IntegrationResult{Level: it.level}
+	x := make([]float64, it.dim)
+	target := it.absTol > 0 || it.relTol > 0
+	for r := 0; r < it.maxRep; r++ {
+		res.Estimates = append(res.Estimates, it.replicate(r, f, x))
+		res.Evaluations += it.n
+		if r+1 < it.minRep {
+			continue
+		}
+		res.summarize()
+		if !target {
+			break
+		}
+		if res.High-res.Mean <= math.Max(it.absTol, it.relTol*math.Abs(res.Mean)) {
+			res.Converged = true
+			break
+		}
+	}
+	return res
+}
+
+// replicate returns the mean of f over the n points of replication r.
+func (it *Integrator) replicate(r int, f func(x []float64) float64, x []float64) float64 {
+	switch {
+	case it.g != nil && r == 0:
+		it.g.ResetStartSubstream()
+	case it.g != nil:
+		it.g.ResetNextSubstream()
+	case r == 0:
+		it.p.ResetStartSubstream()
+	default:
+		it.p.ResetNextSubstream()
+	}
+	sum := 0.0
+	for i := 0; i < it.n; i++ {
+		if it.g != nil {
+			for j := range x {
+				x[j] = it.g.RandU01()
+			}
+		} else {
+			it.p.Next(x)
+		}
+		sum += f(x)
+	}
+	return sum / float64(it.n)
+}
+
+func (res *IntegrationResult) summarize() {
+	m := len(res.Estimates)
+	var v float64
+	res.Mean, v = meanVariance(res.Estimates)
+	res.StdError = math.Sqrt(v / float64(m))
+	h := tHalfWidth(res.StdError, float64(m-1), res.Level)
+	res.Low, res.High = res.Mean-h, res.Mean+h
+}
+
+// tHalfWidth returns the half-width of the two-sided confidence interval
+// of the given level for a mean with standard error se, from the Student
+// t distribution with df degrees of freedom (the normal if df is +Inf).
+func tHalfWidth(se, df, level float64) float64 {
+	t, err := NewStudentT(df)
+	if err != nil {
+		return math.NaN()
+	}
+	return t.Quantile(0.5+level/2) * se
+}
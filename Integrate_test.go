@@ -0,0 +1,86 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// genz returns the product peak integrand of dimension d, whose integral
+// over [0,1]^d is prod_j (atan(c (1 - w)) + atan(c w)) / c.
+func genz(d int, c, w float64) (f func(x []float64) float64, integral float64) {
+	integral = math.Pow((math.Atan(c*(1-w))+math.Atan(c*w))/c, float64(d))
+	f = func(x []float64) float64 {
+		p := 1.0
+		for _, v := range x[:d] {
+			p /= 1 + c*c*(v-w)*(v-w)
+		}
+		return p
+	}
+	return f, integral
+}
+
+func TestIntegrator(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	const d = 4
+	f, exact := genz(d, 1, 0.3)
+
+	g := New("mc")
+	mc, err := NewMCIntegrator(g, d, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := mc.Integrate(f)
+	if len(res.Estimates) != 10 || res.Evaluations != 10000 || res.Converged {
+		t.Errorf("%d replications, %d evaluations, converged %v", len(res.Estimates), res.Evaluations, res.Converged)
+	}
+	if math.Abs(res.Mean-exact) > 4*res.StdError {
+		t.Errorf("Monte Carlo estimate %v +- %v, wanted %v", res.Mean, res.StdError, exact)
+	}
+	if h := res.High - res.Mean; math.Abs(h/res.StdError-2.262157) > 1e-5 {
+		t.Errorf("half-width %v standard errors, wanted t(9) quantile 2.262157", h/res.StdError)
+	}
+
+	// reproducible from the same substream
+	g.ResetStartStream()
+	again := mc.Integrate(f)
+	if again.Mean != res.Mean {
+		t.Errorf("second run %v, wanted %v", again.Mean, res.Mean)
+	}
+
+	// sequential stopping at the target precision
+	mc.SetTolerance(0, 1e-3)
+	g.ResetNextSubstream()
+	res = mc.Integrate(f)
+	if !res.Converged || res.High-res.Mean > 1e-3*res.Mean {
+		t.Errorf("not converged: %v +- %v after %d replications", res.Mean, res.High-res.Mean, len(res.Estimates))
+	}
+	mcEvals := res.Evaluations
+
+	// randomized QMC reaches ten times that precision with fewer
+	// evaluations
+	sobol, _ := NewSobol(d)
+	sobol.SetRandomization(New("sobol"), OwenScrambling)
+	halton, _ := NewHalton(d)
+	halton.SetRandomization(New("halton"))
+	z, _ := CBCVector(1021, d, nil)
+	lattice, _ := NewLattice(1021, z)
+	lattice.SetRandomization(New("lattice"))
+	for _, p := range []PointSet{sobol, halton, lattice} {
+		n := 1024
+		if p == PointSet(lattice) {
+			n = 1021
+		}
+		q, err := NewQMCIntegrator(p, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.SetTolerance(0, 1e-4)
+		res := q.Integrate(f)
+		if !res.Converged || res.Evaluations >= mcEvals {
+			t.Errorf("%T: %d evaluations, Monte Carlo %d, converged %v", p, res.Evaluations, mcEvals, res.Converged)
+		}
+		if math.Abs(res.Mean-exact) > 4*res.StdError {
+			t.Errorf("%T: estimate %v +- %v, wanted %v", p, res.Mean, res.StdError, exact)
+		}
+	}
+}
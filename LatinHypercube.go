@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// LatinHypercube returns a Latin hypercube sample of n >= 1 points in
+// [0,1)^d, d >= 1, as an n x d matrix: in every dimension, each of the n
+// intervals [k/n, (k+1)/n) holds exactly one point. For each dimension in
+// turn, it draws a random permutation of the cells with n-1 calls to
+// RandInt, then the position of each point within its cell with n calls
+// to RandU01, so the design is a function of the stream state only and
+// can be regenerated from a logged seed.
+func LatinHypercube(g *RngStream, n, d int) [][]float64 {
+	if n < 1 || d < 1 {
+		panic(ErrDimension)
+	}
+	x := newMatrix(n, d)
+	perm := make([]int, n)
+	for j := 0; j < d; j++ {
+		for i := range perm {
+			perm[i] = i
+		}
+		for i := n - 1; i > 0; i-- {
+			k := g.RandInt(0, i)
+			perm[i], perm[k] = perm[k], perm[i]
+		}
+		for i := 0; i < n; i++ {
+			x[i][j] = (float64(perm[i]) + g.RandU01()) / float64(n)
+		}
+	}
+	return x
+}
+
+// MaximinLatinHypercube returns a Latin hypercube sample of n points in
+// [0,1)^d whose smallest distance between two points has been increased
+// by iters steps of random search, starting from LatinHypercube(g, n, d).
+// Each step exchanges the coordinates of two random points in a random
+// dimension, with three calls to RandInt, and keeps the exchange unless
+// it decreases the smallest distance; the result is still a Latin
+// hypercube.
+func MaximinLatinHypercube(g *RngStream, n, d, iters int) [][]float64 {
+	x := LatinHypercube(g, n, d)
+	improveLatinHypercube(g, x, iters, func(x [][]float64) float64 {
+		return -minDistance(x)
+	})
+	return x
+}
+
+// CorrelationReducedLatinHypercube returns a Latin hypercube sample of n
+// points in [0,1)^d whose columns have been made closer to uncorrelated
+// by iters steps of random search, starting from LatinHypercube(g, n, d).
+// Each step exchanges the coordinates of two random points in a random
+// dimension, with three calls to RandInt, and keeps the exchange unless
+// it increases the sum of the squared correlations between columns; the
+// result is still a Latin hypercube.
+func CorrelationReducedLatinHypercube(g *RngStream, n, d, iters int) [][]float64 {
+	x := LatinHypercube(g, n, d)
+	improveLatinHypercube(g, x, iters, correlationCriterion)
+	return x
+}
+
+// improveLatinHypercube runs iters steps of the random exchange search on
+// x, minimizing crit.
+func improveLatinHypercube(g *RngStream, x [][]float64, iters int, crit func([][]float64) float64) {
+	n, d := len(x), len(x[0])
+	if n < 2 {
+		return
+	}
+	best := crit(x)
+	for it := 0; it < iters; it++ {
+		j := g.RandInt(0, d-1)
+		a := g.RandInt(0, n-1)
+		b := g.RandInt(0, n-1)
+		if a == b {
+			continue
+		}
+		x[a][j], x[b][j] = x[b][j], x[a][j]
+		if c := crit(x); c <= best {
+			best = c
+		} else {
+			x[a][j], x[b][j] = x[b][j], x[a][j]
+		}
+	}
+}
+
+// minDistance returns the smallest Euclidean distance between two rows
+// of x.
+func minDistance(x [][]float64) float64 {
+	best := math.Inf(1)
+	for a := range x {
+		for b := a + 1; b < len(x); b++ {
+			s := 0.0
+			for j := range x[a] {
+				t := x[a][j] - x[b][j]
+				s += t * t
+			}
+			best = math.Min(best, s)
+		}
+	}
+	return math.Sqrt(best)
+}
+
+// correlationCriterion returns the sum of the squared Pearson
+// correlations between distinct columns of x.
+func correlationCriterion(x [][]float64) float64 {
+	n, d := len(x), len(x[0])
+	mean := make([]float64, d)
+	sd := make([]float64, d)
+	for j := 0; j < d; j++ {
+		for i := 0; i < n; i++ {
+			mean[j] += x[i][j]
+		}
+		mean[j] /= float64(n)
+		for i := 0; i < n; i++ {
+			t := x[i][j] - mean[j]
+			sd[j] += t * t
+		}
+		sd[j] = math.Sqrt(sd[j])
+	}
+	sum := 0.0
+	for j := 0; j < d; j++ {
+		for k := j + 1; k < d; k++ {
+			c := 0.0
+			for i := 0; i < n; i++ {
+				c += (x[i][j] - mean[j]) * (x[i][k] - mean[k])
+			}
+			r := c / (sd[j] * sd[k])
+			sum += r * r
+		}
+	}
+	return sum
+}
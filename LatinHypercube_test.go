@@ -0,0 +1,61 @@
+package rngstream
+
+import "testing"
+
+// isLatinHypercube reports whether every column of x has exactly one
+// point in each of the n cells.
+func isLatinHypercube(x [][]float64) bool {
+	n := len(x)
+	for j := range x[0] {
+		seen := make([]bool, n)
+		for i := range x {
+			k := int(x[i][j] * float64(n))
+			if k < 0 || k >= n || seen[k] {
+				return false
+			}
+			seen[k] = true
+		}
+	}
+	return true
+}
+
+func TestLatinHypercube(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("lhs")
+
+	x := LatinHypercube(g, 50, 4)
+	if len(x) != 50 || len(x[0]) != 4 || !isLatinHypercube(x) {
+		t.Fatalf("not a 50 x 4 Latin hypercube")
+	}
+
+	// regenerated from the same stream state
+	g.ResetStartStream()
+	y := LatinHypercube(g, 50, 4)
+	for i := range x {
+		for j := range x[i] {
+			if x[i][j] != y[i][j] {
+				t.Fatalf("design not reproducible at (%d, %d)", i, j)
+			}
+		}
+	}
+
+	g.ResetNextSubstream()
+	plain := LatinHypercube(g, 30, 3)
+	g.ResetStartSubstream()
+	mm := MaximinLatinHypercube(g, 30, 3, 2000)
+	if !isLatinHypercube(mm) {
+		t.Errorf("maximin design is not a Latin hypercube")
+	}
+	if minDistance(mm) <= minDistance(plain) {
+		t.Errorf("maximin distance %v, not above the initial %v", minDistance(mm), minDistance(plain))
+	}
+
+	g.ResetStartSubstream()
+	cr := CorrelationReducedLatinHypercube(g, 30, 3, 2000)
+	if !isLatinHypercube(cr) {
+		t.Errorf("correlation-reduced design is not a Latin hypercube")
+	}
+	if c0, c := correlationCriterion(plain), correlationCriterion(cr); c >= c0 || c > 1e-3 {
+		t.Errorf("squared correlations %v, initially %v", c, c0)
+	}
+}
@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Lattice generates the n points x_i = frac(i z / n + D), i = 0, ..., n-1,
+// of a rank-1 lattice rule in [0,1)^d, with generating vector z and, with
+// randomization, a uniform random shift D. Unlike sequences, lattice rules
+// are designed for their number of points n, and the whole point set
+// should be used.
+//
+// The shift is drawn from a stream, from the beginning of its current
+// substream, and the reset methods mirror those of Sobol. Without
+// randomization, the first point is the origin.
+type Lattice struct {
+	n     int
+	z     []int
+	shift []float64 // nil without randomization
+	g     *RngStream
+	index uint64 // index of the next point
+}
+
+// NewLattice returns the lattice rule with n >= 1 points and generating
+// vector z, whose components must lie in {0, ..., n-1} and should be
+// coprime with n, without randomization. Its dimension is len(z).
+func NewLattice(n int, z []int) (*Lattice, error) {
+	if len(z) == 0 {
+		return nil, ErrDimension
+	}
+	if n < 1 {
+		return nil, paramError("lattice: need n >= 1 points")
+	}
+	for _, c := range z {
+		if c < 0 || c >= n {
+			return nil, paramError("lattice: generating vector components must lie in [0, %d)", n)
+		}
+	}
+	return &Lattice{n: n, z: append([]int(nil), z...)}, nil
+}
+
+// KorobovVector returns the Korobov generating vector (1, a, a^2, ...,
+// a^(dim-1)) mod n.
+func KorobovVector(n, dim, a int) []int {
+	z := make([]int, dim)
+	c := 1 % n
+	b := uint64((a%n + n) % n)
+	for j := range z {
+		z[j] = c
+		c = int(mulMod(uint64(c), b, uint64(n)))
+	}
+	return z
+}
+
+// mulMod returns a b mod n, computed without overflow.
+func mulMod(a, b, n uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, n)
+}
+
+// latticeWeights returns the product weights gamma, or the default
+// gamma_j = 1/j^2 if gamma is nil.
+func latticeWeights(dim int, gamma []float64) ([]float64, error) {
+	if gamma == nil {
+		gamma = make([]float64, dim)
+		for j := range gamma {
+			gamma[j] = 1 / float64((j+1)*(j+1))
+		}
+		return gamma, nil
+	}
+	if len(gamma) < dim {
+		return nil, ErrDimension
+	}
+	for _, w := range gamma[:dim] {
+		if !(w >= 0) {
+			return nil, paramError("lattice: need weights >= 0")
+		}
+	}
+	return gamma, nil
+}
+
+// latticeKernel returns omega(r/n) = 2 pi^2 B_2(r/n), r = 0, ..., n-1,
+// with the Bernoulli polynomial B_2(x) = x^2 - x + 1/6: the kernel of the
+// Korobov space of smoothness 2.
+func latticeKernel(n int) []float64 {
+	omega := make([]float64, n)
+	for r := range omega {
+		x := float64(r) / float64(n)
+		omega[r] = 2 * math.Pi * math.Pi * (x*x - x + 1.0/6)
+	}
+	return omega
+}
+
+// latticeError returns the squared worst-case error, for randomly shifted
+// rules, in the Korobov space of smoothness 2 with product weights gamma:
+// -1 + (1/n) sum_k prod_j (1 + gamma_j omega(frac(k z_j / n))).
+func latticeError(n int, z []int, gamma []float64) float64 {
+	omega := latticeKernel(n)
+	sum := 0.0
+	for k := 0; k < n; k++ {
+		p := 1.0
+		for j, c := range z {
+			p *= 1 + gamma[j]*omega[int(int64(k)*int64(c)%int64(n))]
+		}
+		sum += p
+	}
+	return sum/float64(n) - 1
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// SearchKorobov returns the parameter a of the Korobov generating vector
+// of dimension dim for n >= 2 points that minimizes the worst-case error
+// of randomly shifted rules in the Korobov space of smoothness 2 with
+// product weights gamma, which default to gamma_j = 1/j^2 if nil. The
+// exhaustive search over a coprime with n costs O(n^2 dim) operations.
+func SearchKorobov(n, dim int, gamma []float64) (int, error) {
+	if dim < 1 {
+		return 0, ErrDimension
+	}
+	if n < 2 {
+		return 0, paramError("lattice: need n >= 2 points")
+	}
+	gamma, err := latticeWeights(dim, gamma)
+	if err != nil {
+		return 0, err
+	}
+	best, bestErr := 1, math.Inf(1)
+	// a and n - a give the same error, as B_2 is symmetric about 1/2
+	for a := 1; a <= n/2; a++ {
+		if gcd(a, n) != 1 {
+			continue
+		}
+		if e := latticeError(n, KorobovVector(n, dim, a), gamma); e < bestErr {
+			best, bestErr = a, e
+		}
+	}
+	return best, nil
+}
+
+// CBCVector returns a generating vector of dimension dim for n >= 2
+// points built component by component: z_1 = 1, then each z_j coprime
+// with n minimizes the worst-case error of randomly shifted rules in the
+// Korobov space of smoothness 2 with product weights gamma, which default
+// to gamma_j = 1/j^2 if nil, given the components chosen before. The
+// construction costs O(n^2 dim) operations.
+func CBCVector(n, dim int, gamma []float64) ([]int, error) {
+	if dim < 1 {
+		return nil, ErrDimension
+	}
+	if n < 2 {
+		return nil, paramError("lattice: need n >= 2 points")
+	}
+	gamma, err := latticeWeights(dim, gamma)
+	if err != nil {
+		return nil, err
+	}
+	omega := latticeKernel(n)
+	z := make([]int, dim)
+	z[0] = 1
+	// p[k] holds the product over the chosen components for point k
+	p := make([]float64, n)
+	for k := range p {
+		p[k] = 1 + gamma[0]*omega[k]
+	}
+	for j := 1; j < dim; j++ {
+		best, bestErr := 1, math.Inf(1)
+		for c := 1; c <= n/2; c++ {
+			if gcd(c, n) != 1 {
+				continue
+			}
+			sum := 0.0
+			for k, pk := range p {
+				sum += pk * (1 + gamma[j]*omega[int(int64(k)*int64(c)%int64(n))])
+			}
+			if sum < bestErr {
+				best, bestErr = c, sum
+			}
+		}
+		z[j] = best
+		for k := range p {
+			p[k] *= 1 + gamma[j]*omega[int(int64(k)*int64(best)%int64(n))]
+		}
+	}
+	return z, nil
+}
+
+// Dim returns the dimension of the points.
+func (l *Lattice) Dim() int {
+	return len(l.z)
+}
+
+// Size returns the number of points n.
+func (l *Lattice) Size() int {
+	return l.n
+}
+
+// GeneratingVector returns a copy of the generating vector.
+func (l *Lattice) GeneratingVector() []int {
+	return append([]int(nil), l.z...)
+}
+
+// SetRandomization sets the stream of the random shift and draws it from
+// the beginning of its current substream, with Dim calls to RandU01;
+// g == nil removes the shift. The point set restarts at its first point.
+func (l *Lattice) SetRandomization(g *RngStream) {
+	l.g = g
+	l.randomize()
+}
+
+func (l *Lattice) randomize() {
+	l.shift = nil
+	if l.g != nil {
+		l.g.ResetStartSubstream()
+		l.shift = make([]float64, len(l.z))
+		for j := range l.shift {
+			l.shift[j] = l.g.RandU01()
+		}
+	}
+	l.index = 0
+}
+
+// Next stores the next point in dst, whose length must be at least Dim.
+// It panics after the n points of the rule.
+func (l *Lattice) Next(dst []float64) {
+	if len(dst) < len(l.z) {
+		panic(ErrDimension)
+	}
+	if l.index >= uint64(l.n) {
+		panic(paramError("lattice: point set exhausted after %d points", l.n))
+	}
+	n := uint64(l.n)
+	for j, c := range l.z {
+		x := float64(mulMod(l.index, uint64(c), n)) / float64(n)
+		if l.shift != nil {
+			x += l.shift[j]
+			if x >= 1 {
+				x--
+			}
+		}
+		dst[j] = x
+	}
+	l.index++
+}
+
+// SkipTo moves to point i <= n (counting from 0), so that the next call
+// to Next returns it.
+func (l *Lattice) SkipTo(i uint64) {
+	if i > uint64(l.n) {
+		panic(paramError("lattice: cannot skip beyond %d points", l.n))
+	}
+	l.index = i
+}
+
+// Index returns the index of the next point.
+func (l *Lattice) Index() uint64 {
+	return l.index
+}
+
+// ResetStartStream restarts the point set at its first point, with the
+// shift drawn from the first substream of the stream.
+func (l *Lattice) ResetStartStream() {
+	if l.g != nil {
+		l.g.ResetStartStream()
+	}
+	l.randomize()
+}
+
+// ResetStartSubstream restarts the point set at its first point, with the
+// same shift.
+func (l *Lattice) ResetStartSubstream() {
+	l.SkipTo(0)
+}
+
+// ResetNextSubstream restarts the point set at its first point, with a
+// new shift drawn from the next substream of the stream.
+func (l *Lattice) ResetNextSubstream() {
+	if l.g != nil {
+		l.g.ResetNextSubstream()
+	}
+	l.randomize()
+}
@@ -0,0 +1,152 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLatticeConstruction(t *testing.T) {
+	z := KorobovVector(31, 4, 3)
+	for j, w := range []int{1, 3, 9, 27} {
+		if z[j] != w {
+			t.Errorf("Korobov vector %v, wanted 1, 3, 9, 27", z)
+			break
+		}
+	}
+
+	// products beyond 64 bits for more than 2^32 points
+	const big = 1<<40 + 1
+	if z := KorobovVector(big, 3, big-1); z[1] != big-1 || z[2] != 1 {
+		t.Errorf("Korobov vector %v, wanted 1, %d, 1", z, big-1)
+	}
+	lb, err := NewLattice(big, []int{big - 1, 3, big - 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lb.SkipTo(big - 1)
+	xb := make([]float64, 3)
+	lb.Next(xb)
+	for j, w := range []float64{1, big - 3, 2} {
+		if xb[j] != w/big {
+			t.Errorf("last point %v, wanted (1, %d, 2)/%d", xb, big-3, big)
+			break
+		}
+	}
+
+	const n, dim = 251, 6
+	gamma, _ := latticeWeights(dim, nil)
+	cbc, err := CBCVector(n, dim, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := SearchKorobov(n, dim, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eCBC := latticeError(n, cbc, gamma)
+	eKor := latticeError(n, KorobovVector(n, dim, a), gamma)
+	// both beat the average over all Korobov vectors by a wide margin
+	avg := 0.0
+	for b := 1; b < n; b++ {
+		avg += latticeError(n, KorobovVector(n, dim, b), gamma)
+	}
+	avg /= n - 1
+	if !(eCBC < avg/3) || !(eKor < avg/3) {
+		t.Errorf("errors CBC %v, Korobov %v, average %v", eCBC, eKor, avg)
+	}
+	if cbc[0] != 1 {
+		t.Errorf("CBC vector %v starts with %d", cbc, cbc[0])
+	}
+
+	if _, err := NewLattice(10, []int{1, 10}); err == nil {
+		t.Errorf("expected error for a component >= n")
+	}
+	if _, err := CBCVector(n, 3, []float64{1}); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
+
+func TestLattice(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("lattice")
+	const n, dim = 1021, 5
+	z, _ := CBCVector(n, dim, nil)
+	l, err := NewLattice(n, z)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without shift, every coordinate runs over {0, 1/n, ..., (n-1)/n}
+	x := newMatrix(n, dim)
+	for i := range x {
+		l.Next(x[i])
+	}
+	for j := 0; j < dim; j++ {
+		seen := make(map[int]bool)
+		for _, p := range x {
+			seen[int(math.Round(p[j]*n))] = true
+		}
+		if len(seen) != n {
+			t.Errorf("coordinate %d takes %d values", j, len(seen))
+		}
+	}
+	l.SkipTo(7)
+	y := make([]float64, dim)
+	l.Next(y)
+	if y[1] != x[7][1] {
+		t.Errorf("SkipTo(7) gives %v, wanted %v", y, x[7])
+	}
+
+	// the shifted rule is unbiased, with a standard error far below that
+	// of Monte Carlo for the smooth integrand prod_j (1 + (x_j - 1/2))
+	l.SetRandomization(g)
+	var sum, sq float64
+	const reps = 50
+	for r := 0; r < reps; r++ {
+		est := 0.0
+		for i := 0; i < n; i++ {
+			l.Next(y)
+			p := 1.0
+			for _, v := range y {
+				p *= 0.5 + v
+			}
+			est += p
+		}
+		est /= n
+		sum += est
+		sq += est * est
+		l.ResetNextSubstream()
+	}
+	m := sum / reps
+	se := math.Sqrt((sq/reps - m*m) / (reps - 1))
+	if math.Abs(m-1) > 4*se {
+		t.Errorf("mean %v +- %v, wanted 1", m, se)
+	}
+	mc := math.Sqrt((math.Pow(13.0/12, dim) - 1) / n)
+	if se > mc/10 {
+		t.Errorf("standard error %v, Monte Carlo gives %v", se, mc)
+	}
+
+	// reset semantics as Sobol
+	l.ResetStartStream()
+	l.Next(y)
+	first := y[0]
+	l.ResetStartSubstream()
+	l.Next(y)
+	if y[0] != first {
+		t.Errorf("ResetStartSubstream changed the shift")
+	}
+	l.ResetNextSubstream()
+	l.Next(y)
+	if y[0] == first {
+		t.Errorf("ResetNextSubstream kept the shift")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic after n points")
+		}
+	}()
+	l.SkipTo(n)
+	l.Next(y)
+}
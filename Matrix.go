@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Small dense linear algebra helpers shared by the multivariate samplers.
+// Matrices are stored row-major as [][]float64.
+
+// newMatrix allocates an n x m matrix of zeros.
+func newMatrix(n, m int) [][]float64 {
+	a := make([][]float64, n)
+	for i := range a {
+		a[i] = make([]float64, m)
+	}
+	return a
+}
+
+// isSquare reports whether a is a non-empty n x n matrix.
+func isSquare(a [][]float64) bool {
+	if len(a) == 0 {
+		return false
+	}
+	for _, row := range a {
+		if len(row) != len(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// cholesky returns the lower triangular factor L with a = L L^T. The
+// second result is false if a is not symmetric positive definite.
+func cholesky(a [][]float64) ([][]float64, bool) {
+	if !isSquare(a) {
+		return nil, false
+	}
+	n := len(a)
+	l := newMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			if math.Abs(a[i][j]-a[j][i]) > 1e-12*(math.Abs(a[i][j])+math.Abs(a[j][i])+1) {
+				return nil, false
+			}
+		}
+		for j := 0; j <= i; j++ {
+			sum := a[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				if !(sum > 0) {
+					return nil, false
+				}
+				l[i][i] = math.Sqrt(sum)
+			} else {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l, true
+}
+
+// invertLower returns the inverse of the lower triangular matrix l.
+func invertLower(l [][]float64) [][]float64 {
+	n := len(l)
+	inv := newMatrix(n, n)
+	for j := 0; j < n; j++ {
+		inv[j][j] = 1 / l[j][j]
+		for i := j + 1; i < n; i++ {
+			sum := 0.0
+			for k := j; k < i; k++ {
+				sum -= l[i][k] * inv[k][j]
+			}
+			inv[i][j] = sum / l[i][i]
+		}
+	}
+	return inv
+}
+
+// lowerTimesTranspose writes b b^T into dst, for b lower triangular.
+func lowerTimesTranspose(b, dst [][]float64) {
+	n := len(b)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := 0; k <= j; k++ {
+				sum += b[i][k] * b[j][k]
+			}
+			dst[i][j] = sum
+			dst[j][i] = sum
+		}
+	}
+}
+
+// transposeTimesLower writes b^T b into dst, for b lower triangular.
+func transposeTimesLower(b, dst [][]float64) {
+	n := len(b)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := i; k < n; k++ {
+				sum += b[k][i] * b[k][j]
+			}
+			dst[i][j] = sum
+			dst[j][i] = sum
+		}
+	}
+}
+
+// checkOutput panics unless dst is at least n x n.
+func checkOutput(dst [][]float64, n int) {
+	if len(dst) < n {
+		panic(ErrDimension)
+	}
+	for i := 0; i < n; i++ {
+		if len(dst[i]) < n {
+			panic(ErrDimension)
+		}
+	}
+}
+
+// solveCholesky returns the solution x of L L^T x = b, for the Cholesky
+// factor l.
+func solveCholesky(l [][]float64, b []float64) []float64 {
+	n := len(l)
+	x := append([]float64(nil), b...)
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= l[i][k] * x[k]
+		}
+		x[i] /= l[i][i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			x[i] -= l[k][i] * x[k]
+		}
+		x[i] /= l[i][i]
+	}
+	return x
+}
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"errors"
+)
+
+// ErrNotPositiveDefinite is returned when a covariance (or correlation)
+// matrix is not symmetric positive definite.
+var ErrNotPositiveDefinite = errors.New("rngstream: matrix is not symmetric positive definite")
+
+// ErrDimension is returned when the dimensions of the arguments
+// do not agree.
+var ErrDimension = errors.New("rngstream: dimension mismatch")
+
+// MultiNormal is a multivariate normal distribution given by its mean
+// vector and covariance matrix. The Cholesky factor of the covariance is
+// computed once, when the distribution is built.
+type MultiNormal struct {
+	mean []float64
+	chol [][]float64
+}
+
+// NewMultiNormal returns the multivariate normal distribution with the
+// given mean vector and covariance matrix. The covariance must be a
+// symmetric positive definite matrix whose size matches the mean.
+func NewMultiNormal(mean []float64, cov [][]float64) (*MultiNormal, error) {
+	if len(mean) == 0 || len(cov) != len(mean) || !isSquare(cov) {
+		return nil, ErrDimension
+	}
+	l, ok := cholesky(cov)
+	if !ok {
+		return nil, ErrNotPositiveDefinite
+	}
+	mn := new(MultiNormal)
+	mn.mean = append([]float64(nil), mean...)
+	mn.chol = l
+	return mn, nil
+}
+
+// Dim returns the dimension of the distribution.
+func (mn *MultiNormal) Dim() int {
+	return len(mn.mean)
+}
+
+// Sample writes a variate of the distribution into dst, which must have
+// length at least Dim(). It makes exactly Dim() calls to RandU01, and
+// component i of the underlying standard normal vector is obtained by
+// inversion from the i-th uniform, so that two streams used with common
+// random numbers stay aligned component by component.
+func (mn *MultiNormal) Sample(g *RngStream, dst []float64) {
+	n := len(mn.mean)
+	if len(dst) < n {
+		panic(ErrDimension)
+	}
+	z := make([]float64, n)
+	for i := range z {
+		z[i] = StdNormalInv(g.RandU01())
+	}
+	for i := 0; i < n; i++ {
+		sum := mn.mean[i]
+		for k := 0; k <= i; k++ {
+			sum += mn.chol[i][k] * z[k]
+		}
+		dst[i] = sum
+	}
+}
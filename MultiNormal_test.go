@@ -0,0 +1,78 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStdNormalInv(t *testing.T) {
+	for _, u := range []float64{1e-300, 1e-12, 0.001, 0.02425, 0.3, 0.5, 0.9, 0.999999, 1 - 1e-12} {
+		x := StdNormalInv(u)
+		// compare in the smaller tail, where the relative error matters
+		want, got := u, StdNormalCDF(x)
+		if u > 0.5 {
+			want, got = 1-u, 0.5*math.Erfc(x/math.Sqrt2)
+		}
+		if math.Abs(got-want) > 1e-12*want {
+			t.Errorf("StdNormalInv(%v) = %v, CDF gives back %v", u, x, got)
+		}
+	}
+	if StdNormalInv(0.5) != 0 {
+		t.Errorf("StdNormalInv(0.5) = %v, wanted 0", StdNormalInv(0.5))
+	}
+}
+
+func TestMultiNormal(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("mvn")
+
+	mean := []float64{1, -2, 0.5}
+	cov := [][]float64{{4, 1.2, -0.6}, {1.2, 1, 0.3}, {-0.6, 0.3, 2}}
+	mn, err := NewMultiNormal(mean, cov)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 100000
+	x := make([]float64, 3)
+	var sum [3]float64
+	var cross [3][3]float64
+	for r := 0; r < n; r++ {
+		mn.Sample(g, x)
+		for i := 0; i < 3; i++ {
+			sum[i] += x[i]
+			for j := 0; j < 3; j++ {
+				cross[i][j] += (x[i] - mean[i]) * (x[j] - mean[j])
+			}
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if math.Abs(sum[i]/n-mean[i]) > 0.02 {
+			t.Errorf("mean[%d] = %v, wanted %v", i, sum[i]/n, mean[i])
+		}
+		for j := 0; j < 3; j++ {
+			if math.Abs(cross[i][j]/n-cov[i][j]) > 0.05 {
+				t.Errorf("cov[%d][%d] = %v, wanted %v", i, j, cross[i][j]/n, cov[i][j])
+			}
+		}
+	}
+
+	// Common random numbers: same substream gives the same vectors.
+	g.ResetStartSubstream()
+	y := make([]float64, 3)
+	mn.Sample(g, y)
+	g.ResetStartSubstream()
+	mn.Sample(g, x)
+	for i := range x {
+		if x[i] != y[i] {
+			t.Errorf("component %d differs after ResetStartSubstream", i)
+		}
+	}
+
+	if _, err := NewMultiNormal([]float64{0, 0}, [][]float64{{1, 2}, {2, 1}}); err != ErrNotPositiveDefinite {
+		t.Errorf("indefinite covariance: got error %v", err)
+	}
+	if _, err := NewMultiNormal([]float64{0}, [][]float64{{1, 0}, {0, 1}}); err != ErrDimension {
+		t.Errorf("mismatched dimension: got error %v", err)
+	}
+}
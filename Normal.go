@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Coefficients of the rational approximations used by StdNormalInv
+// (P. J. Acklam's algorithm), central region then tails.
+var (
+	normInvA = [6]float64{
+		-3.969683028665376e+01, 2.209460984245205e+02,
+		-2.759285104469687e+02, 1.383577518672690e+02,
+		-3.066479806614716e+01, 2.506628277459239e+00}
+	normInvB = [5]float64{
+		-5.447609879822406e+01, 1.615858368580409e+02,
+		-1.556989798598866e+02, 6.680131188771972e+01,
+		-1.328068155288572e+01}
+	normInvC = [6]float64{
+		-7.784894002430293e-03, -3.223964580411365e-01,
+		-2.400758277161838e+00, -2.549732539343734e+00,
+		4.374664141464968e+00, 2.938163982698783e+00}
+	normInvD = [4]float64{
+		7.784695709041462e-03, 3.224671290700398e-01,
+		2.445134137142996e+00, 3.754408661907416e+00}
+)
+
+const normInvLow float64 = 0.02425
+
+// StdNormalCDF returns the standard normal distribution function at x.
+func StdNormalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// StdNormalInv returns the inverse of the standard normal distribution
+// function at u, for 0 < u < 1. It returns -Inf for u <= 0 and +Inf for
+// u >= 1. The initial rational approximation is refined by one Halley
+// step, which gives close to full double precision over the whole range.
+func StdNormalInv(u float64) float64 {
+	if u <= 0 {
+		return math.Inf(-1)
+	}
+	if u >= 1 {
+		return math.Inf(1)
+	}
+
+	var x float64
+	a, b, c, d := &normInvA, &normInvB, &normInvC, &normInvD
+
+	switch {
+	case u < normInvLow:
+		q := math.Sqrt(-2 * math.Log(u))
+		x = (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	case u <= 1-normInvLow:
+		q := u - 0.5
+		r := q * q
+		x = (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q /
+			(((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+	default:
+		q := math.Sqrt(-2 * math.Log1p(-u))
+		x = -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) /
+			((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+
+	/* Refinement using Halley's rational method */
+	e := StdNormalCDF(x) - u
+	if u > 0.5 {
+		// work with the upper tail to avoid cancellation in the CDF
+		e = (1 - u) - 0.5*math.Erfc(x/math.Sqrt2)
+	}
+	h := e * math.Sqrt(2*math.Pi) * math.Exp(x*x/2)
+	return x - h/(1+x*h/2)
+}
+
+// RandNormal returns a normal variate with mean mu and standard deviation
+// sigma, generated by inversion. Makes one call to RandU01, so streams used
+// for common random numbers stay synchronized.
+func (g *RngStream) RandNormal(mu, sigma float64) float64 {
+	return mu + sigma*StdNormalInv(g.RandU01())
+}
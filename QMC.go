@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+// UniformStream is a source of uniforms organized in substreams, as used
+// by a simulation model. It is satisfied by *RngStream, for Monte Carlo,
+// and by *PointStream, for quasi-Monte Carlo, so that a model written
+// against it can be switched from one to the other without code changes.
+//
+// The Sample methods of the distributions take an *RngStream, and cannot
+// draw from a PointStream. A model written against UniformStream draws
+// its variates by inversion instead, with SampleInversion(d, s), that is
+// d.Quantile(s.RandU01()), which every Invertible distribution supports
+// and which uses exactly one coordinate of the point per variate.
+type UniformStream interface {
+	RandU01() float64
+	ResetStartStream()
+	ResetStartSubstream()
+	ResetNextSubstream()
+}
+
+// PointSet is a sequence of points in [0,1)^d, optionally randomized from
+// a stream, such as Sobol, Halton and Lattice. Its reset methods mirror
+// those of RngStream: ResetStartSubstream returns to the first point with
+// the same randomization, ResetNextSubstream to the first point with a
+// new randomization drawn from the next substream of the randomization
+// stream, and ResetStartStream to the first point with the randomization
+// drawn from its first substream.
+type PointSet interface {
+	Dim() int
+	Next(dst []float64)
+	SkipTo(n uint64)
+	ResetStartStream()
+	ResetStartSubstream()
+	ResetNextSubstream()
+}
+
+// PointStream presents a point set as a UniformStream: successive calls
+// to RandU01 return the successive coordinates of the current point, and
+// substreams correspond to points, so that a model running one
+// replication per substream runs one replication per point. Coordinates
+// beyond the dimension of the point set are taken from a padding stream,
+// whose substreams follow the points, if one is given; otherwise
+// requesting them panics.
+//
+// Without randomization, Halton and lattice point sets start at the
+// origin, whose coordinates are 0; randomize them before feeding models
+// that invert distribution functions.
+type PointStream struct {
+	p      PointSet
+	pad    *RngStream
+	cur    []float64
+	j      int
+	index  uint64 // index of the current point
+	next   uint64 // index of the next point of p, if known
+	known  bool   // whether next is known
+	loaded bool   // whether cur holds the current point
+}
+
+// NewPointStream returns the stream view of p, positioned at its first
+// point, with padding stream pad, which may be nil.
+func NewPointStream(p PointSet, pad *RngStream) *PointStream {
+	s := &PointStream{p: p, pad: pad, cur: make([]float64, p.Dim())}
+	s.ResetStartStream()
+	return s
+}
+
+// PointSet returns the underlying point set.
+func (s *PointStream) PointSet() PointSet {
+	return s.p
+}
+
+// RandU01 returns the next coordinate of the current point. Points are
+// generated on first use, so that a finite point set can be run through
+// to its last point with one ResetNextSubstream per point.
+func (s *PointStream) RandU01() float64 {
+	if s.j < len(s.cur) {
+		if !s.loaded {
+			if !s.known || s.next != s.index {
+				s.p.SkipTo(s.index)
+			}
+			s.p.Next(s.cur)
+			s.next, s.known, s.loaded = s.index+1, true, true
+		}
+		s.j++
+		return s.cur[s.j-1]
+	}
+	if s.pad == nil {
+		panic(ErrDimension)
+	}
+	return s.pad.RandU01()
+}
+
+// RandInt returns an integer uniformly distributed over {i, ..., j},
+// from one coordinate, as RngStream.RandInt.
+func (s *PointStream) RandInt(i, j int) int {
+	return i + int(float64(j-i+1)*s.RandU01())
+}
+
+// ResetStartStream moves back to the first point, with its first
+// coordinate, keeping the randomization of the point set.
+func (s *PointStream) ResetStartStream() {
+	s.index, s.j = 0, 0
+	s.known, s.loaded = false, false
+	if s.pad != nil {
+		s.pad.ResetStartStream()
+	}
+}
+
+// ResetStartSubstream moves back to the first coordinate of the current
+// point.
+func (s *PointStream) ResetStartSubstream() {
+	s.j = 0
+	if s.pad != nil {
+		s.pad.ResetStartSubstream()
+	}
+}
+
+// ResetNextSubstream moves to the first coordinate of the next point.
+func (s *PointStream) ResetNextSubstream() {
+	s.index++
+	s.j = 0
+	s.loaded = false
+	if s.pad != nil {
+		s.pad.ResetNextSubstream()
+	}
+}
+
+// Index returns the index of the current point.
+func (s *PointStream) Index() uint64 {
+	return s.index
+}
+
+// Randomize draws a new randomization of the point set, from the next
+// substream of its randomization stream, and moves to its first point;
+// the padding stream moves to its next substream.
+func (s *PointStream) Randomize() {
+	s.p.ResetNextSubstream()
+	s.index, s.j = 0, 0
+	s.next, s.known, s.loaded = 0, true, false
+	if s.pad != nil {
+		s.pad.ResetNextSubstream()
+	}
+}
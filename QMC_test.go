@@ -0,0 +1,119 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// Compile-time checks of the implementations of the interfaces.
+var (
+	_ UniformStream = (*RngStream)(nil)
+	_ UniformStream = (*PointStream)(nil)
+	_ PointSet      = (*Sobol)(nil)
+	_ PointSet      = (*Halton)(nil)
+	_ PointSet      = (*Lattice)(nil)
+)
+
+// sumOfExponentials is a model written against UniformStream: the mean of
+// the sum of d exponential variables of rate 1, by inversion, estimated
+// from n replications, one per substream.
+func sumOfExponentials(s UniformStream, d, n int) float64 {
+	est := 0.0
+	for r := 0; r < n; r++ {
+		for j := 0; j < d; j++ {
+			est -= math.Log(1 - s.RandU01())
+		}
+		s.ResetNextSubstream()
+	}
+	return est / float64(n)
+}
+
+func TestPointStream(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	sobol, _ := NewSobol(3)
+	ps := NewPointStream(sobol, nil)
+
+	// the coordinates of the successive points
+	want, _ := NewSobol(3)
+	p := make([]float64, 3)
+	for i := 0; i < 10; i++ {
+		want.Next(p)
+		for j := range p {
+			if u := ps.RandU01(); u != p[j] {
+				t.Fatalf("point %d coordinate %d = %v, wanted %v", i, j, u, p[j])
+			}
+		}
+		ps.ResetStartSubstream()
+		if u := ps.RandU01(); u != p[0] {
+			t.Fatalf("ResetStartSubstream moved to %v", u)
+		}
+		ps.ResetNextSubstream()
+	}
+	ps.ResetStartStream()
+	want.SkipTo(0)
+	want.Next(p)
+	if u := ps.RandU01(); u != p[0] {
+		t.Errorf("ResetStartStream gives %v, wanted %v", u, p[0])
+	}
+
+	// the same model runs on Monte Carlo and quasi-Monte Carlo streams; the
+	// randomized point sets give unbiased and far more accurate estimates
+	const d, n, reps = 3, 1024, 20
+	mcStream := New("mc")
+	halton, _ := NewHalton(d)
+	halton.SetRandomization(New("halton"))
+	z, _ := CBCVector(n, d, nil)
+	lattice, _ := NewLattice(n, z)
+	lattice.SetRandomization(New("lattice"))
+	sobol.SetRandomization(New("sobol"), OwenScrambling)
+
+	mcSum, mcSq := 0.0, 0.0
+	for r := 0; r < reps; r++ {
+		e := sumOfExponentials(mcStream, d, n)
+		mcSum += e
+		mcSq += e * e
+	}
+	mcSE := math.Sqrt((mcSq/reps - mcSum*mcSum/reps/reps) / (reps - 1))
+	for _, set := range []PointSet{sobol, halton, lattice} {
+		s := NewPointStream(set, nil)
+		sum, sq := 0.0, 0.0
+		for r := 0; r < reps; r++ {
+			e := sumOfExponentials(s, d, n)
+			sum += e
+			sq += e * e
+			s.Randomize()
+		}
+		m := sum / reps
+		se := math.Sqrt((sq/reps - m*m) / (reps - 1))
+		if math.Abs(m-d) > 4*se+1e-9 {
+			t.Errorf("%T: mean %v +- %v, wanted %d", set, m, se, d)
+		}
+		if se > mcSE/5 {
+			t.Errorf("%T: standard error %v, Monte Carlo gives %v", set, se, mcSE)
+		}
+	}
+
+	// coordinates beyond the dimension come from the padding stream,
+	// whose substreams follow the points
+	pad := New("pad")
+	check := *pad
+	s := NewPointStream(lattice, pad)
+	s.ResetNextSubstream()
+	for j := 0; j < d; j++ {
+		s.RandU01()
+	}
+	check.ResetNextSubstream()
+	if u, w := s.RandU01(), check.RandU01(); u != w {
+		t.Errorf("padding coordinate %v, wanted %v", u, w)
+	}
+
+	defer func() {
+		if recover() != ErrDimension {
+			t.Errorf("expected ErrDimension without padding")
+		}
+	}()
+	s = NewPointStream(lattice, nil)
+	for j := 0; j <= d; j++ {
+		s.RandU01()
+	}
+}
@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// HaarOrthogonal writes into dst, an n x n matrix with n = len(dst), a
+// random orthogonal matrix from the Haar (uniform) distribution on O(n).
+// It makes exactly n*n calls to RandU01, one per entry of a matrix of
+// standard normals generated by inversion, whose QR decomposition gives
+// the result. The columns of Q are multiplied by the signs of the diagonal
+// of R; without this correction the QR factor is not Haar distributed.
+func HaarOrthogonal(g *RngStream, dst [][]float64) {
+	haar(g, dst, false)
+}
+
+// HaarRotation writes into dst, an n x n matrix with n = len(dst), a
+// random rotation matrix from the Haar distribution on SO(n), that is, an
+// orthogonal matrix with determinant +1. It makes exactly n*n calls to
+// RandU01, as HaarOrthogonal.
+func HaarRotation(g *RngStream, dst [][]float64) {
+	haar(g, dst, true)
+}
+
+func haar(g *RngStream, dst [][]float64, rotation bool) {
+	n := len(dst)
+	checkOutput(dst, n)
+	a := newMatrix(n, n)
+	for i := range a {
+		for j := range a[i] {
+			a[i][j] = StdNormalInv(g.RandU01())
+		}
+	}
+
+	// Householder QR: a is overwritten by R, the reflectors are kept in vs
+	vs := newMatrix(n, n)
+	det := 1.0 // determinant of Q
+	for k := 0; k < n-1; k++ {
+		norm := 0.0
+		for i := k; i < n; i++ {
+			norm += a[i][k] * a[i][k]
+		}
+		norm = math.Sqrt(norm)
+		if norm == 0 {
+			continue
+		}
+		alpha := -norm
+		if a[k][k] < 0 {
+			alpha = norm
+		}
+		v := vs[k]
+		vnorm := 0.0
+		for i := k; i < n; i++ {
+			v[i] = a[i][k]
+		}
+		v[k] -= alpha
+		for i := k; i < n; i++ {
+			vnorm += v[i] * v[i]
+		}
+		if vnorm == 0 {
+			continue
+		}
+		for j := k; j < n; j++ {
+			s := 0.0
+			for i := k; i < n; i++ {
+				s += v[i] * a[i][j]
+			}
+			s *= 2 / vnorm
+			for i := k; i < n; i++ {
+				a[i][j] -= s * v[i]
+			}
+		}
+		for i := k; i < n; i++ {
+			v[i] /= math.Sqrt(vnorm)
+		}
+		det = -det
+	}
+
+	// Q = H_0 H_1 ... H_{n-2}, applied to the identity in reverse order
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			dst[i][j] = 0
+		}
+		dst[i][i] = 1
+	}
+	for k := n - 2; k >= 0; k-- {
+		v := vs[k]
+		for j := 0; j < n; j++ {
+			s := 0.0
+			for i := k; i < n; i++ {
+				s += v[i] * dst[i][j]
+			}
+			for i := k; i < n; i++ {
+				dst[i][j] -= 2 * s * v[i]
+			}
+		}
+	}
+
+	// sign correction: Q D with D = diag(sign(R_kk))
+	for k := 0; k < n; k++ {
+		if a[k][k] < 0 {
+			det = -det
+			for i := 0; i < n; i++ {
+				dst[i][k] = -dst[i][k]
+			}
+		}
+	}
+	if rotation && det < 0 {
+		for i := 0; i < n; i++ {
+			dst[i][0] = -dst[i][0]
+		}
+	}
+}
+
+// Wishart is the Wishart distribution W(scale, nu) of n x n positive
+// definite matrices, the distribution of sum_k x_k x_k^T for nu
+// independent N(0, scale) vectors x_k when nu is an integer. Its mean is
+// nu * scale.
+type Wishart struct {
+	chol    [][]float64
+	chi     []*Gamma
+	inverse bool
+}
+
+// NewWishart returns the Wishart distribution with the given positive
+// definite n x n scale matrix and nu > n-1 degrees of freedom.
+func NewWishart(scale [][]float64, nu float64) (*Wishart, error) {
+	return newWishart(scale, nu, false)
+}
+
+// NewInverseWishart returns the inverse Wishart distribution with the
+// given positive definite n x n scale matrix psi and nu > n-1 degrees of
+// freedom: X is inverse Wishart with scale psi if X^-1 is Wishart with
+// scale psi^-1. Its mean is psi/(nu - n - 1) for nu > n + 1.
+func NewInverseWishart(psi [][]float64, nu float64) (*Wishart, error) {
+	return newWishart(psi, nu, true)
+}
+
+func newWishart(scale [][]float64, nu float64, inverse bool) (*Wishart, error) {
+	if !isSquare(scale) {
+		return nil, ErrDimension
+	}
+	n := len(scale)
+	if !(nu > float64(n-1)) || math.IsInf(nu, 1) {
+		return nil, paramError("wishart: need finite nu > n-1")
+	}
+	l, ok := cholesky(scale)
+	if !ok {
+		return nil, ErrNotPositiveDefinite
+	}
+	if inverse {
+		// sample the Wishart with scale psi^-1 = L^-T L^-1 and invert
+		li := invertLower(l)
+		p := newMatrix(n, n)
+		transposeTimesLower(li, p)
+		l, ok = cholesky(p)
+		if !ok {
+			return nil, ErrNotPositiveDefinite
+		}
+	}
+	w := &Wishart{chol: l, inverse: inverse}
+	w.chi = make([]*Gamma, n)
+	for i := range w.chi {
+		w.chi[i], _ = NewGamma((nu-float64(i))/2, 2)
+	}
+	return w, nil
+}
+
+// Dim returns the size n of the matrices.
+func (w *Wishart) Dim() int {
+	return len(w.chol)
+}
+
+// Sample writes a random matrix into dst, which must be at least n x n,
+// using the Bartlett decomposition. It makes exactly n(n+1)/2 calls to
+// RandU01: for each row i, the i normals below the diagonal, then the
+// chi-square variate on the diagonal, all generated by inversion. Its
+// work matrices are allocated per call, so that concurrent calls with
+// different streams are safe.
+func (w *Wishart) Sample(g *RngStream, dst [][]float64) {
+	n := len(w.chol)
+	checkOutput(dst, n)
+	a, b := newMatrix(n, n), newMatrix(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			a[i][j] = StdNormalInv(g.RandU01())
+		}
+		a[i][i] = math.Sqrt(w.chi[i].Quantile(g.RandU01()))
+	}
+	// b = L a, lower triangular
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := j; k <= i; k++ {
+				sum += w.chol[i][k] * a[k][j]
+			}
+			b[i][j] = sum
+		}
+	}
+	if !w.inverse {
+		lowerTimesTranspose(b, dst)
+		return
+	}
+	transposeTimesLower(invertLower(b), dst)
+}
+
+// LKJ is the Lewandowski-Kurowicka-Joe distribution of random d x d
+// correlation matrices, with density proportional to det(R)^(eta-1).
+// eta = 1 gives matrices uniformly distributed over the set of
+// correlation matrices; larger eta concentrates the mass near the
+// identity.
+type LKJ struct {
+	d     int
+	eta   float64
+	betas []*Beta
+}
+
+// NewLKJ returns the LKJ distribution of d x d correlation matrices,
+// d >= 2, with parameter eta > 0.
+func NewLKJ(d int, eta float64) (*LKJ, error) {
+	if d < 2 {
+		return nil, ErrDimension
+	}
+	if !(eta > 0) || math.IsInf(eta, 1) {
+		return nil, paramError("LKJ: need finite eta > 0")
+	}
+	c := &LKJ{d: d, eta: eta, betas: make([]*Beta, d-1)}
+	beta := eta + float64(d-2)/2
+	c.betas[0], _ = NewBeta(beta, beta)
+	for k := 2; k < d; k++ {
+		beta -= 0.5
+		c.betas[k-1], _ = NewBeta(float64(k)/2, beta)
+	}
+	return c, nil
+}
+
+// Dim returns the size d of the matrices.
+func (c *LKJ) Dim() int {
+	return c.d
+}
+
+// Sample writes a random correlation matrix into dst, which must be at
+// least d x d, using the onion method. It makes exactly d(d-1)/2 + d - 2
+// calls to RandU01: one for the beta variate giving the first correlation,
+// then for each new row k = 2, ..., d-1, one for a beta variate and k for
+// a uniform direction (see UniformSphere), all generated by inversion.
+func (c *LKJ) Sample(g *RngStream, dst [][]float64) {
+	d := c.d
+	checkOutput(dst, d)
+	for i := 0; i < d; i++ {
+		dst[i][i] = 1
+	}
+	r := 2*c.betas[0].Sample(g) - 1
+	dst[0][1], dst[1][0] = r, r
+	w := make([]float64, d)
+	for k := 2; k < d; k++ {
+		y := c.betas[k-1].Sample(g)
+		UniformSphere(g, w[:k])
+		sy := math.Sqrt(y)
+		sub := make([][]float64, k)
+		for i := range sub {
+			sub[i] = dst[i][:k]
+		}
+		l, _ := cholesky(sub)
+		for i := 0; i < k; i++ {
+			z := 0.0
+			for j := 0; j <= i; j++ {
+				z += l[i][j] * w[j] * sy
+			}
+			dst[k][i], dst[i][k] = z, z
+		}
+	}
+}
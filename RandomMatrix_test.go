@@ -0,0 +1,207 @@
+package rngstream
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestHaar(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("haar")
+	const n, reps = 4, 20000
+	q := newMatrix(n, n)
+	for _, rotation := range []bool{false, true} {
+		var tr, tr2, q11, q11sq float64
+		negdet := 0
+		for r := 0; r < reps; r++ {
+			if rotation {
+				HaarRotation(g, q)
+			} else {
+				HaarOrthogonal(g, q)
+			}
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					dot := 0.0
+					for k := 0; k < n; k++ {
+						dot += q[k][i] * q[k][j]
+					}
+					want := 0.0
+					if i == j {
+						want = 1
+					}
+					if math.Abs(dot-want) > 1e-12 {
+						t.Fatalf("Q^T Q differs from identity at (%d, %d): %v", i, j, dot)
+					}
+				}
+			}
+			if det4(q) < 0 {
+				negdet++
+			}
+			s := q[0][0] + q[1][1] + q[2][2] + q[3][3]
+			tr += s / reps
+			tr2 += s * s / reps
+			q11 += q[0][0] / reps
+			q11sq += q[0][0] * q[0][0] / reps
+		}
+		// under the Haar measure on O(n): E[tr Q] = 0 and E[(tr Q)^2] = 1
+		if math.Abs(q11) > 0.02 || math.Abs(q11sq-1.0/n) > 0.01 {
+			t.Errorf("rotation=%v: E[Q11] = %v, E[Q11^2] = %v", rotation, q11, q11sq)
+		}
+		if !rotation && (math.Abs(tr) > 0.03 || math.Abs(tr2-1) > 0.05) {
+			t.Errorf("E[tr Q] = %v, E[(tr Q)^2] = %v", tr, tr2)
+		}
+		if rotation && negdet > 0 {
+			t.Errorf("%d rotations with negative determinant", negdet)
+		}
+		if !rotation && math.Abs(float64(negdet)/reps-0.5) > 0.02 {
+			t.Errorf("fraction of negative determinants %v", float64(negdet)/reps)
+		}
+	}
+}
+
+// det4 returns the determinant of a 4 x 4 matrix by cofactor expansion.
+func det4(a [][]float64) float64 {
+	det3 := func(r [3]int, c [3]int) float64 {
+		m := func(i, j int) float64 { return a[r[i]][c[j]] }
+		return m(0, 0)*(m(1, 1)*m(2, 2)-m(1, 2)*m(2, 1)) -
+			m(0, 1)*(m(1, 0)*m(2, 2)-m(1, 2)*m(2, 0)) +
+			m(0, 2)*(m(1, 0)*m(2, 1)-m(1, 1)*m(2, 0))
+	}
+	det := 0.0
+	sign := 1.0
+	for j := 0; j < 4; j++ {
+		var c [3]int
+		k := 0
+		for jj := 0; jj < 4; jj++ {
+			if jj != j {
+				c[k] = jj
+				k++
+			}
+		}
+		det += sign * a[0][j] * det3([3]int{1, 2, 3}, c)
+		sign = -sign
+	}
+	return det
+}
+
+func TestWishart(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("wishart")
+	scale := [][]float64{{2, 0.5, 0}, {0.5, 1, -0.3}, {0, -0.3, 1.5}}
+	const nu, reps = 7.5, 20000
+	w, err := NewWishart(scale, nu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iw, err := NewInverseWishart(scale, nu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := newMatrix(3, 3)
+	mw, miw := newMatrix(3, 3), newMatrix(3, 3)
+	for r := 0; r < reps; r++ {
+		w.Sample(g, x)
+		if _, ok := cholesky(x); !ok {
+			t.Fatalf("Wishart sample not positive definite: %v", x)
+		}
+		for i := range x {
+			for j := range x {
+				mw[i][j] += x[i][j] / reps
+			}
+		}
+		iw.Sample(g, x)
+		for i := range x {
+			for j := range x {
+				miw[i][j] += x[i][j] / reps
+			}
+		}
+	}
+	for i := range x {
+		for j := range x {
+			if want := nu * scale[i][j]; math.Abs(mw[i][j]-want) > 0.1 {
+				t.Errorf("Wishart mean[%d][%d] = %v, wanted %v", i, j, mw[i][j], want)
+			}
+			if want := scale[i][j] / (nu - 4); math.Abs(miw[i][j]-want) > 0.05 {
+				t.Errorf("inverse Wishart mean[%d][%d] = %v, wanted %v", i, j, miw[i][j], want)
+			}
+		}
+	}
+	// concurrent sampling with one stream per goroutine gives the same
+	// matrices as sequential sampling
+	streams := make([]RngStream, 4)
+	for i := range streams {
+		streams[i] = *g
+		g.ResetNextSubstream()
+	}
+	sample := func(s RngStream) [][]float64 {
+		out := newMatrix(3*100, 3)
+		for k := 0; k < 100; k++ {
+			iw.Sample(&s, out[3*k:])
+		}
+		return out
+	}
+	got := make([][][]float64, len(streams))
+	var wg sync.WaitGroup
+	for i := range streams {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i] = sample(streams[i])
+		}(i)
+	}
+	wg.Wait()
+	for i := range streams {
+		want := sample(streams[i])
+		for k := range want {
+			for j := range want[k] {
+				if got[i][k][j] != want[k][j] {
+					t.Fatalf("concurrent sample differs from sequential one")
+				}
+			}
+		}
+	}
+
+	if _, err := NewWishart(scale, 1.5); err == nil {
+		t.Errorf("expected error for nu <= n-1")
+	}
+}
+
+func TestLKJ(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("lkj")
+	const d, reps = 4, 20000
+	for _, eta := range []float64{1, 3} {
+		c, err := NewLKJ(d, eta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := newMatrix(d, d)
+		var mean, sq [d][d]float64
+		for k := 0; k < reps; k++ {
+			c.Sample(g, r)
+			if _, ok := cholesky(r); !ok {
+				t.Fatalf("sample is not positive definite: %v", r)
+			}
+			for i := 0; i < d; i++ {
+				if r[i][i] != 1 {
+					t.Fatalf("diagonal entry %v", r[i][i])
+				}
+				for j := 0; j < d; j++ {
+					mean[i][j] += r[i][j] / reps
+					sq[i][j] += r[i][j] * r[i][j] / reps
+				}
+			}
+		}
+		// each off-diagonal entry is 2 Beta(b, b) - 1, b = eta - 1 + d/2
+		want := 1 / (2*(eta-1+d/2.0) + 1)
+		for i := 0; i < d; i++ {
+			for j := 0; j < i; j++ {
+				if math.Abs(mean[i][j]) > 0.02 || math.Abs(sq[i][j]-want) > 0.01 {
+					t.Errorf("eta=%v (%d, %d): mean %v, variance %v, wanted %v",
+						eta, i, j, mean[i][j], sq[i][j], want)
+				}
+			}
+		}
+	}
+}
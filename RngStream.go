@@ -383,6 +383,21 @@ func (g *RngStream) ResetStartSubstream() {
 	g.cg = g.bg
 }
 
+// resetSubstream reinitializes the stream to the beginning of its
+// substream r, counting from its initial state Ig. It is equivalent to
+// ResetStartStream followed by r calls to ResetNextSubstream, but jumps
+// there in O(log r) operations.
+func (g *RngStream) resetSubstream(r int64) {
+	var B1 = [3][3]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}
+	var B2 = [3][3]float64{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}
+
+	matPowModM(&a1p76, &B1, m1, r)
+	matPowModM(&a2p76, &B2, m2, r)
+	matVecModM(&B1, g.ig[:3], g.bg[:3], m1)
+	matVecModM(&B2, g.ig[3:], g.bg[3:], m2)
+	g.cg = g.bg
+}
+
 // SetPackageSeed sets the initial seed s0 of the package to the six
 // integers in the vector seed. The first 3 integers in the seed must
 // all be less than m1 = 4294967087, and not all 0; and the last 3
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// SDE describes the one-dimensional stochastic differential equation
+// dX = Drift(t, X) dt + Diffusion(t, X) dW.
+type SDE struct {
+	Drift     func(t, x float64) float64
+	Diffusion func(t, x float64) float64
+	// DiffusionDeriv is the derivative of Diffusion with respect to x.
+	// It is only needed by the Milstein scheme.
+	DiffusionDeriv func(t, x float64) float64
+
+	nonNegative bool // the process lives on [0, +Inf)
+}
+
+// NewOrnsteinUhlenbeck returns the Ornstein-Uhlenbeck process
+// dX = theta (mu - X) dt + sigma dW, with theta >= 0 (theta = 0 gives a
+// Brownian motion), finite mu and sigma > 0.
+func NewOrnsteinUhlenbeck(theta, mu, sigma float64) (*SDE, error) {
+	if !(theta >= 0) || math.IsInf(theta, 1) || math.IsNaN(mu) || math.IsInf(mu, 0) {
+		return nil, paramError("ornstein-uhlenbeck: need finite theta >= 0 and mu")
+	}
+	if !(sigma > 0) || math.IsInf(sigma, 1) {
+		return nil, paramError("ornstein-uhlenbeck: need finite sigma > 0")
+	}
+	return &SDE{
+		Drift:          func(t, x float64) float64 { return theta * (mu - x) },
+		Diffusion:      func(t, x float64) float64 { return sigma },
+		DiffusionDeriv: func(t, x float64) float64 { return 0 },
+	}, nil
+}
+
+// NewCIR returns the Cox-Ingersoll-Ross process
+// dX = kappa (theta - X) dt + sigma sqrt(X) dW, with kappa >= 0,
+// theta >= 0 and sigma > 0. The process is non-negative, and simulators
+// of it must start at x0 >= 0. Negative values, which the discretization
+// can produce, are replaced by zero inside the drift and diffusion ("full
+// truncation").
+func NewCIR(kappa, theta, sigma float64) (*SDE, error) {
+	if !(kappa >= 0) || !(theta >= 0) || math.IsInf(kappa, 1) || math.IsInf(theta, 1) {
+		return nil, paramError("cir: need finite kappa >= 0 and theta >= 0")
+	}
+	if !(sigma > 0) || math.IsInf(sigma, 1) {
+		return nil, paramError("cir: need finite sigma > 0")
+	}
+	return &SDE{
+		Drift: func(t, x float64) float64 { return kappa * (theta - math.Max(x, 0)) },
+		Diffusion: func(t, x float64) float64 {
+			return sigma * math.Sqrt(math.Max(x, 0))
+		},
+		DiffusionDeriv: func(t, x float64) float64 {
+			if x <= 0 {
+				return 0
+			}
+			return sigma / (2 * math.Sqrt(x))
+		},
+		nonNegative: true,
+	}, nil
+}
+
+// Scheme selects the discretization used by an SDESimulator.
+type Scheme int
+
+const (
+	// EulerMaruyama is the Euler-Maruyama scheme, of strong order 1/2.
+	EulerMaruyama Scheme = iota
+	// Milstein is the Milstein scheme, of strong order 1. It requires
+	// SDE.DiffusionDeriv.
+	Milstein
+)
+
+// SDESimulator simulates paths of an SDE over [0, horizon] with a fixed
+// number of equal time steps. Each step makes exactly one call to RandU01,
+// and the Brownian increment is obtained by inversion.
+type SDESimulator struct {
+	sde      *SDE
+	scheme   Scheme
+	x0       float64
+	horizon  float64
+	steps    int
+	observer func(i int, t, x float64)
+}
+
+// NewSDESimulator returns a simulator for sde, started at x0 at time 0,
+// that uses the given scheme with steps > 0 equal steps up to horizon > 0.
+// x0 must be finite, and non-negative for a non-negative process such as
+// the CIR process.
+func NewSDESimulator(sde *SDE, scheme Scheme, x0, horizon float64, steps int) (*SDESimulator, error) {
+	if sde == nil || sde.Drift == nil || sde.Diffusion == nil {
+		return nil, paramError("sde: need drift and diffusion functions")
+	}
+	if scheme == Milstein && sde.DiffusionDeriv == nil {
+		return nil, paramError("sde: the Milstein scheme needs DiffusionDeriv")
+	}
+	if scheme != EulerMaruyama && scheme != Milstein {
+		return nil, paramError("sde: unknown scheme")
+	}
+	if !(horizon > 0) || math.IsInf(horizon, 1) || steps <= 0 {
+		return nil, paramError("sde: need finite horizon > 0 and steps > 0")
+	}
+	if math.IsNaN(x0) || math.IsInf(x0, 0) || (sde.nonNegative && x0 < 0) {
+		return nil, paramError("sde: need a finite start x0, >= 0 for a non-negative process")
+	}
+	return &SDESimulator{sde: sde, scheme: scheme, x0: x0, horizon: horizon, steps: steps}, nil
+}
+
+// SetObserver sets a function called with the step index i, time t and
+// state x at the start of a path (i = 0) and after each step
+// (i = 1, ..., steps). A nil function removes the observer.
+func (s *SDESimulator) SetObserver(f func(i int, t, x float64)) {
+	s.observer = f
+}
+
+// Steps returns the number of time steps of a path.
+func (s *SDESimulator) Steps() int {
+	return s.steps
+}
+
+// Path simulates one path from the current state of g and returns its
+// terminal value. If dst is not nil, it must have length at least
+// Steps()+1 and receives the state at times 0, h, 2h, ..., horizon.
+func (s *SDESimulator) Path(g *RngStream, dst []float64) float64 {
+	if dst != nil && len(dst) < s.steps+1 {
+		panic(ErrDimension)
+	}
+	h := s.horizon / float64(s.steps)
+	sqh := math.Sqrt(h)
+	x := s.x0
+	if dst != nil {
+		dst[0] = x
+	}
+	if s.observer != nil {
+		s.observer(0, 0, x)
+	}
+	for i := 0; i < s.steps; i++ {
+		t := float64(i) * h
+		dw := sqh * StdNormalInv(g.RandU01())
+		b := s.sde.Diffusion(t, x)
+		next := x + s.sde.Drift(t, x)*h + b*dw
+		if s.scheme == Milstein {
+			next += 0.5 * b * s.sde.DiffusionDeriv(t, x) * (dw*dw - h)
+		}
+		x = next
+		if dst != nil {
+			dst[i+1] = x
+		}
+		if s.observer != nil {
+			s.observer(i+1, float64(i+1)*h, x)
+		}
+	}
+	return x
+}
+
+// Paths simulates n paths and returns them. Path k uses the k-th substream
+// counted from the current one: the first path starts at the beginning of
+// the current substream of g, and g is moved to its next substream with
+// ResetNextSubstream after each path. Running Paths twice from the same
+// stream state, e.g. after ResetStartStream, couples the paths of
+// different parameter sets through common random numbers.
+func (s *SDESimulator) Paths(g *RngStream, n int) [][]float64 {
+	paths := make([][]float64, n)
+	for k := range paths {
+		paths[k] = make([]float64, s.steps+1)
+		g.ResetStartSubstream()
+		s.Path(g, paths[k])
+		g.ResetNextSubstream()
+	}
+	return paths
+}
+
+// Terminals is like Paths but only returns the terminal value of each
+// path.
+func (s *SDESimulator) Terminals(g *RngStream, n int) []float64 {
+	x := make([]float64, n)
+	for k := range x {
+		g.ResetStartSubstream()
+		x[k] = s.Path(g, nil)
+		g.ResetNextSubstream()
+	}
+	return x
+}
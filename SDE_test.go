@@ -0,0 +1,128 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSDESimulator(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("sde")
+
+	// Ornstein-Uhlenbeck: X(T) ~ N(mu + (x0-mu) e^{-theta T}, sigma^2 (1-e^{-2 theta T})/(2 theta))
+	ou, err := NewOrnsteinUhlenbeck(1.5, 2, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim, err := NewSDESimulator(ou, EulerMaruyama, 0, 1, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := sim.Terminals(g, 5000)
+	m, v := 0.0, 0.0
+	for _, xi := range x {
+		m += xi / float64(len(x))
+	}
+	for _, xi := range x {
+		v += (xi - m) * (xi - m) / float64(len(x)-1)
+	}
+	wantM := 2 - 2*math.Exp(-1.5)
+	wantV := 0.64 * (1 - math.Exp(-3)) / 3
+	if math.Abs(m-wantM) > 0.02 || math.Abs(v-wantV) > 0.01 {
+		t.Errorf("OU terminal moments (%v, %v), wanted (%v, %v)", m, v, wantM, wantV)
+	}
+
+	// CIR: E[X(T)] = theta + (x0 - theta) e^{-kappa T}
+	cir, err := NewCIR(2, 0.04, 0.2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim, err = NewSDESimulator(cir, EulerMaruyama, 0.1, 1, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = 0
+	for _, xi := range sim.Terminals(g, 5000) {
+		m += xi / 5000
+	}
+	if want := 0.04 + 0.06*math.Exp(-2); math.Abs(m-want) > 0.002 {
+		t.Errorf("CIR terminal mean %v, wanted %v", m, want)
+	}
+
+	// Geometric Brownian motion: the Milstein scheme is closer to the exact
+	// solution driven by the same increments than Euler-Maruyama.
+	gbm := &SDE{
+		Drift:          func(t, x float64) float64 { return 0.1 * x },
+		Diffusion:      func(t, x float64) float64 { return 0.5 * x },
+		DiffusionDeriv: func(t, x float64) float64 { return 0.5 },
+	}
+	var errEuler, errMilstein float64
+	for _, scheme := range []Scheme{EulerMaruyama, Milstein} {
+		sim, _ := NewSDESimulator(gbm, scheme, 1, 1, 16)
+		g.ResetStartStream()
+		e := 0.0
+		for k := 0; k < 2000; k++ {
+			xT := sim.Path(g, nil)
+			g.ResetStartSubstream()
+			w := 0.0
+			for i := 0; i < 16; i++ {
+				w += math.Sqrt(1.0/16) * StdNormalInv(g.RandU01())
+			}
+			exact := math.Exp((0.1-0.125)*1 + 0.5*w)
+			e += math.Abs(xT-exact) / 2000
+			g.ResetNextSubstream()
+		}
+		if scheme == Milstein {
+			errMilstein = e
+		} else {
+			errEuler = e
+		}
+	}
+	if !(errMilstein < errEuler/2) {
+		t.Errorf("strong errors: Milstein %v, Euler %v", errMilstein, errEuler)
+	}
+
+	// paths for different parameters are coupled through the substreams
+	bm1, _ := NewOrnsteinUhlenbeck(0, 0, 1)
+	bm3, _ := NewOrnsteinUhlenbeck(0, 0, 3)
+	s1, _ := NewSDESimulator(bm1, EulerMaruyama, 0, 1, 10)
+	s2, _ := NewSDESimulator(bm3, EulerMaruyama, 0, 1, 10)
+	g.ResetStartStream()
+	p1 := s1.Paths(g, 3)
+	g.ResetStartStream()
+	p2 := s2.Paths(g, 3)
+	for k := range p1 {
+		for i := range p1[k] {
+			if math.Abs(3*p1[k][i]-p2[k][i]) > 1e-12 {
+				t.Fatalf("path %d step %d not coupled: %v vs %v", k, i, p1[k][i], p2[k][i])
+			}
+		}
+	}
+	if p1[0][10] == p1[1][10] {
+		t.Errorf("different paths share their substream")
+	}
+
+	// the observer sees every step, ending with the terminal value
+	calls, last, inOrder := 0, 0.0, true
+	s1.SetObserver(func(i int, tm, x float64) {
+		inOrder = inOrder && i == calls && math.Abs(tm-float64(i)/10) < 1e-12
+		calls++
+		last = x
+	})
+	if xT := s1.Path(g, nil); calls != 11 || last != xT || !inOrder {
+		t.Errorf("observer called %d times, last state %v, terminal %v", calls, last, xT)
+	}
+
+	if _, err := NewSDESimulator(&SDE{Drift: gbm.Drift, Diffusion: gbm.Diffusion}, Milstein, 0, 1, 1); err == nil {
+		t.Errorf("expected error for Milstein without DiffusionDeriv")
+	}
+	if _, err := NewOrnsteinUhlenbeck(1, 0, -1); err == nil {
+		t.Errorf("expected error for negative sigma")
+	}
+	if _, err := NewCIR(-1, 0.04, 0.2); err == nil {
+		t.Errorf("expected error for negative kappa")
+	}
+	if _, err := NewSDESimulator(cir, EulerMaruyama, -0.1, 1, 10); err == nil {
+		t.Errorf("expected error for a CIR process started below zero")
+	}
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Dirichlet is the Dirichlet distribution on the probability simplex,
+// with positive concentration parameters alpha.
+type Dirichlet struct {
+	alpha []float64
+}
+
+// NewDirichlet returns the Dirichlet distribution with the given
+// concentration parameters, which must all be positive. At least two
+// parameters are required.
+func NewDirichlet(alpha []float64) (*Dirichlet, error) {
+	if len(alpha) < 2 {
+		return nil, ErrDimension
+	}
+	for _, a := range alpha {
+		if !(a > 0) || math.IsInf(a, 1) {
+			return nil, paramError("dirichlet: need finite alpha > 0")
+		}
+	}
+	return &Dirichlet{alpha: append([]float64(nil), alpha...)}, nil
+}
+
+// Dim returns the number of components of a variate.
+func (d *Dirichlet) Dim() int {
+	return len(d.alpha)
+}
+
+// Sample writes a variate into dst, which must have length at least
+// Dim(). The components are normalized gamma variates (see RandGamma), so
+// the number of calls to RandU01 is random.
+func (d *Dirichlet) Sample(g *RngStream, dst []float64) {
+	n := len(d.alpha)
+	if len(dst) < n {
+		panic(ErrDimension)
+	}
+	lmax := math.Inf(-1)
+	for i, a := range d.alpha {
+		dst[i] = g.randLogGamma(a)
+		if dst[i] > lmax {
+			lmax = dst[i]
+		}
+	}
+	// normalize in log space so that tiny alphas cannot give 0/0
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		dst[i] = math.Exp(dst[i] - lmax)
+		sum += dst[i]
+	}
+	for i := 0; i < n; i++ {
+		dst[i] /= sum
+	}
+}
+
+// Multinomial is the distribution of the counts in each of len(p)
+// categories after n independent trials with category probabilities p.
+type Multinomial struct {
+	n int
+	p []float64
+}
+
+// NewMultinomial returns the multinomial distribution with n >= 0 trials
+// and category probabilities p. The probabilities must be non-negative;
+// they are normalized to sum to one.
+func NewMultinomial(n int, p []float64) (*Multinomial, error) {
+	if n < 0 {
+		return nil, paramError("multinomial: need n >= 0")
+	}
+	if len(p) == 0 {
+		return nil, ErrDimension
+	}
+	sum := 0.0
+	for _, pi := range p {
+		if !(pi >= 0) || math.IsInf(pi, 1) {
+			return nil, paramError("multinomial: need finite probabilities >= 0")
+		}
+		sum += pi
+	}
+	if !(sum > 0) {
+		return nil, paramError("multinomial: probabilities sum to zero")
+	}
+	mn := &Multinomial{n: n, p: make([]float64, len(p))}
+	for i, pi := range p {
+		mn.p[i] = pi / sum
+	}
+	return mn, nil
+}
+
+// Dim returns the number of categories.
+func (mn *Multinomial) Dim() int {
+	return len(mn.p)
+}
+
+// Sample writes the category counts into dst, which must have length at
+// least Dim(). Counts are generated one category at a time from their
+// conditional binomial distributions by inversion; this makes exactly
+// Dim()-1 calls to RandU01.
+func (mn *Multinomial) Sample(g *RngStream, dst []int) {
+	k := len(mn.p)
+	if len(dst) < k {
+		panic(ErrDimension)
+	}
+	left := mn.n
+	rest := 1.0
+	for i := 0; i < k-1; i++ {
+		u := g.RandU01()
+		if left == 0 || rest <= 0 {
+			dst[i] = 0
+			continue
+		}
+		pi := mn.p[i] / rest
+		if pi > 1 {
+			pi = 1
+		}
+		dst[i] = binomialInv(u, left, pi)
+		left -= dst[i]
+		rest -= mn.p[i]
+	}
+	dst[k-1] = left
+}
+
+// UniformSimplex writes into dst a point uniformly distributed on the
+// probability simplex {x : x_i >= 0, sum x_i = 1} of dimension len(dst)-1,
+// using normalized exponential spacings. Makes len(dst) calls to RandU01.
+func UniformSimplex(g *RngStream, dst []float64) {
+	sum := 0.0
+	for i := range dst {
+		dst[i] = -math.Log(g.RandU01())
+		sum += dst[i]
+	}
+	for i := range dst {
+		dst[i] /= sum
+	}
+}
+
+// UniformSphere writes into dst a point uniformly distributed on the
+// surface of the unit sphere in R^n, n = len(dst), by normalizing a vector
+// of independent standard normals generated by inversion. Makes n calls to
+// RandU01, unless all n normals are exactly zero and the vector is drawn
+// again.
+func UniformSphere(g *RngStream, dst []float64) {
+	for {
+		norm2 := 0.0
+		for i := range dst {
+			dst[i] = StdNormalInv(g.RandU01())
+			norm2 += dst[i] * dst[i]
+		}
+		if norm2 > 0 || len(dst) == 0 {
+			r := math.Sqrt(norm2)
+			for i := range dst {
+				dst[i] /= r
+			}
+			return
+		}
+	}
+}
+
+// UniformBall writes into dst a point uniformly distributed inside the
+// unit ball in R^n, n = len(dst). A direction is drawn as in UniformSphere
+// and the radius as U^(1/n). Makes n+1 calls to RandU01.
+func UniformBall(g *RngStream, dst []float64) {
+	UniformSphere(g, dst)
+	r := math.Pow(g.RandU01(), 1/float64(len(dst)))
+	for i := range dst {
+		dst[i] *= r
+	}
+}
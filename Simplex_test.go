@@ -0,0 +1,104 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRandBinomial(t *testing.T) {
+	for _, c := range []struct {
+		n int
+		p float64
+	}{{10, 0.3}, {1, 0.5}, {5000, 0.001}, {100000, 0.7}} {
+		// inversion must give a non-decreasing map from u to k
+		prev := 0
+		for i := 1; i < 1000; i++ {
+			k := binomialInv(float64(i)/1000, c.n, c.p)
+			if k < prev || k > c.n {
+				t.Fatalf("binomialInv not monotone for n=%d p=%v", c.n, c.p)
+			}
+			prev = k
+		}
+		mean := float64(c.n) * c.p
+		sd := math.Sqrt(mean * (1 - c.p))
+		med := binomialInv(0.5, c.n, c.p)
+		if math.Abs(float64(med)-mean) > 1+0.1*sd {
+			t.Errorf("median of binomial(%d, %v) = %d, mean %v", c.n, c.p, med, mean)
+		}
+	}
+}
+
+func TestDirichletMultinomial(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("simplex")
+
+	alpha := []float64{0.5, 2, 7.5}
+	d, err := NewDirichlet(alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 20000
+	x := make([]float64, 3)
+	var mean [3]float64
+	for r := 0; r < n; r++ {
+		d.Sample(g, x)
+		for i := range x {
+			mean[i] += x[i] / n
+		}
+	}
+	for i := range alpha {
+		if math.Abs(mean[i]-alpha[i]/10) > 0.01 {
+			t.Errorf("Dirichlet mean[%d] = %v, wanted %v", i, mean[i], alpha[i]/10)
+		}
+	}
+
+	mn, err := NewMultinomial(50, []float64{1, 3, 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, 3)
+	var cmean [3]float64
+	for r := 0; r < n; r++ {
+		mn.Sample(g, counts)
+		if counts[0]+counts[1]+counts[2] != 50 {
+			t.Fatalf("counts %v do not sum to 50", counts)
+		}
+		for i := range counts {
+			cmean[i] += float64(counts[i]) / n
+		}
+	}
+	for i, want := range []float64{5, 15, 30} {
+		if math.Abs(cmean[i]-want) > 0.1 {
+			t.Errorf("multinomial mean[%d] = %v, wanted %v", i, cmean[i], want)
+		}
+	}
+}
+
+func TestUniformSimplexSphereBall(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("sphere")
+	x := make([]float64, 4)
+	inner := 0
+	for r := 0; r < 10000; r++ {
+		UniformSimplex(g, x)
+		if s := x[0] + x[1] + x[2] + x[3]; math.Abs(s-1) > 1e-12 {
+			t.Fatalf("simplex point sums to %v", s)
+		}
+		UniformSphere(g, x)
+		if nrm := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2] + x[3]*x[3]); math.Abs(nrm-1) > 1e-12 {
+			t.Fatalf("sphere point has norm %v", nrm)
+		}
+		UniformBall(g, x)
+		nrm := math.Sqrt(x[0]*x[0] + x[1]*x[1] + x[2]*x[2] + x[3]*x[3])
+		if nrm >= 1 {
+			t.Fatalf("ball point has norm %v", nrm)
+		}
+		// half of the volume of the 4-ball lies within radius 2^(-1/4)
+		if nrm < math.Pow(0.5, 0.25) {
+			inner++
+		}
+	}
+	if math.Abs(float64(inner)/10000-0.5) > 0.02 {
+		t.Errorf("fraction of ball points in inner half-volume = %v", float64(inner)/10000)
+	}
+}
@@ -0,0 +1,447 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"bufio"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// sobolBits is the number of bits of the points; sequences are limited
+// to 2^sobolBits points.
+const sobolBits = 32
+
+// SobolMaxDim is the largest dimension supported by NewSobol, that of the
+// built-in direction numbers of Joe and Kuo (2008). For larger
+// dimensions, read their file new-joe-kuo-6.21201, which goes up to
+// dimension 21201, with ReadJoeKuo and use NewSobolWithDirections.
+const SobolMaxDim = 21
+
+// joeKuoM holds the initial direction numbers m_1, ..., m_s of dimensions
+// 2 to 21 from the file new-joe-kuo-6.21201. The primitive polynomials
+// (s and a in the file) are not stored, as sobolPolynomials regenerates
+// them in the same order.
+var joeKuoM = [][]uint32{
+	{1},
+	{1, 3},
+	{1, 3, 1},
+	{1, 1, 1},
+	{1, 1, 3, 3},
+	{1, 3, 5, 13},
+	{1, 1, 5, 5, 17},
+	{1, 1, 5, 5, 5},
+	{1, 1, 7, 11, 19},
+	{1, 1, 5, 1, 1},
+	{1, 1, 1, 3, 11},
+	{1, 3, 5, 5, 31},
+	{1, 3, 3, 9, 7, 49},
+	{1, 1, 1, 15, 21, 21},
+	{1, 3, 1, 13, 27, 49},
+	{1, 1, 1, 15, 7, 5},
+	{1, 3, 1, 15, 13, 25},
+	{1, 1, 5, 5, 19, 61},
+	{1, 3, 7, 11, 23, 15, 103},
+	{1, 3, 7, 13, 13, 15, 69},
+}
+
+// SobolDirections holds, for dimensions 2, 3, ..., the degree s and the
+// inner coefficients a of a primitive polynomial over GF(2), and the
+// initial direction numbers m_1, ..., m_s, as in the direction number
+// files of Joe and Kuo.
+type SobolDirections struct {
+	s, a []uint32
+	m    [][]uint32
+}
+
+// ReadJoeKuo reads direction numbers in the format of the files of Joe
+// and Kuo, such as new-joe-kuo-6.21201: a header line, then one line
+// "d s a m_1 ... m_s" per dimension d = 2, 3, ...
+func ReadJoeKuo(r io.Reader) (*SobolDirections, error) {
+	dirs := &SobolDirections{}
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		f := strings.Fields(sc.Text())
+		if line == 1 || len(f) == 0 {
+			continue // header, blank lines
+		}
+		var v []uint32
+		for _, x := range f {
+			n, err := strconv.ParseUint(x, 10, 32)
+			if err != nil {
+				return nil, paramError("sobol: line %d: %v", line, err)
+			}
+			v = append(v, uint32(n))
+		}
+		if len(v) < 3 || int(v[0]) != len(dirs.s)+2 || v[1] == 0 || len(v) != 3+int(v[1]) {
+			return nil, paramError("sobol: line %d: malformed entry", line)
+		}
+		s, a, m := v[1], v[2], v[3:]
+		for k, mk := range m {
+			if mk%2 == 0 || mk >= 1<<(k+1) {
+				return nil, paramError("sobol: line %d: m_%d must be odd and below 2^%d", line, k+1, k+1)
+			}
+		}
+		dirs.s = append(dirs.s, s)
+		dirs.a = append(dirs.a, a)
+		dirs.m = append(dirs.m, m)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// Dim returns the largest dimension covered by the direction numbers.
+func (d *SobolDirections) Dim() int {
+	return len(d.s) + 1
+}
+
+// builtinDirections returns the direction numbers of Joe and Kuo for
+// dimensions 2 to dim <= SobolMaxDim.
+func builtinDirections(dim int) *SobolDirections {
+	polys := sobolPolynomials(dim - 1)
+	dirs := &SobolDirections{}
+	for i, p := range polys {
+		s := uint32(bits.Len64(p) - 1)
+		dirs.s = append(dirs.s, s)
+		dirs.a = append(dirs.a, uint32(p>>1)&(1<<(s-1)-1))
+		dirs.m = append(dirs.m, joeKuoM[i])
+	}
+	return dirs
+}
+
+// sobolPolynomials returns the first n primitive polynomials over GF(2),
+// as bit masks, ordered by degree then by their inner coefficients, as in
+// the files of Joe and Kuo: x+1, x^2+x+1, x^3+x+1, x^3+x^2+1, ...
+func sobolPolynomials(n int) []uint64 {
+	var polys []uint64
+	if n > 0 {
+		polys = append(polys, 3) // x+1, for which x = 1
+	}
+	for s := 2; len(polys) < n; s++ {
+		order := uint64(1)<<s - 1
+		factors := primeFactors(order)
+		for a := uint64(0); a < 1<<(s-1) && len(polys) < n; a++ {
+			p := uint64(1)<<s | a<<1 | 1
+			if gf2PowMod(2, order, p) != 1 {
+				continue
+			}
+			primitive := true
+			for _, q := range factors {
+				if gf2PowMod(2, order/q, p) == 1 {
+					primitive = false
+					break
+				}
+			}
+			if primitive {
+				polys = append(polys, p)
+			}
+		}
+	}
+	return polys
+}
+
+// primeFactors returns the distinct prime factors of n.
+func primeFactors(n uint64) []uint64 {
+	var f []uint64
+	for q := uint64(2); q*q <= n; q++ {
+		if n%q == 0 {
+			f = append(f, q)
+			for n%q == 0 {
+				n /= q
+			}
+		}
+	}
+	if n > 1 {
+		f = append(f, n)
+	}
+	return f
+}
+
+// gf2MulMod returns a b mod p for polynomials over GF(2) given as bit
+// masks, with deg a, deg b < deg p <= 31.
+func gf2MulMod(a, b, p uint64) uint64 {
+	deg := bits.Len64(p) - 1
+	var r uint64
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			r ^= a
+		}
+		a <<= 1
+		if a>>deg&1 != 0 {
+			a ^= p
+		}
+	}
+	return r
+}
+
+// gf2PowMod returns a^e mod p for polynomials over GF(2), with
+// deg a < deg p.
+func gf2PowMod(a, e, p uint64) uint64 {
+	r := uint64(1)
+	for ; e > 0; e >>= 1 {
+		if e&1 != 0 {
+			r = gf2MulMod(r, a, p)
+		}
+		a = gf2MulMod(a, a, p)
+	}
+	return r
+}
+
+// splitMix64 returns a well-mixed hash of x (the output function of the
+// SplitMix64 generator).
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// Scrambling is a randomization method of a Sobol sequence.
+type Scrambling int
+
+const (
+	// NoScrambling gives the deterministic Sobol points.
+	NoScrambling Scrambling = iota
+	// DigitalShift XORs every coordinate with a random 32-bit shift.
+	DigitalShift
+	// LinearMatrixScrambling multiplies the generator matrices by random
+	// non-singular lower triangular matrices (Matousek, 1998), followed by
+	// a random digital shift.
+	LinearMatrixScrambling
+	// OwenScrambling applies a nested uniform scramble (Owen, 1995): the
+	// i-th bit of a coordinate is flipped or not according to a random bit
+	// depending on the i-1 bits before it. The random bits are derived
+	// from a 64-bit seed per dimension through a hash function.
+	OwenScrambling
+)
+
+// Sobol generates the points of a Sobol sequence in [0,1)^d, optionally
+// randomized, in Gray code order. The first 2^m points form a (t,m,d)-net
+// for every m, so sample sizes should preferably be powers of two.
+//
+// The randomization is drawn from a stream, from the beginning of its
+// current substream, and the reset methods mirror those of RngStream:
+// ResetStartSubstream returns to the first point with the same
+// randomization, ResetNextSubstream to the first point with the new,
+// independent randomization drawn from the next substream, and
+// ResetStartStream to the first point with the randomization drawn from
+// the first substream. Independent randomizations thus map onto
+// successive substreams.
+//
+// Points are returned at the centers of the cells of width 2^-32, so
+// that they lie in (0,1) as the output of RandU01.
+type Sobol struct {
+	dim    int
+	v      [][sobolBits]uint32 // direction numbers
+	sv     [][sobolBits]uint32 // scrambled direction numbers
+	shift  []uint32
+	seed   []uint64 // Owen scrambling
+	method Scrambling
+	g      *RngStream
+	index  uint64 // index of the next point
+	x      []uint32
+}
+
+// NewSobol returns the Sobol sequence of dimension 1 <= dim <=
+// SobolMaxDim, without randomization, with the built-in direction
+// numbers of Joe and Kuo. It returns ErrDimension above SobolMaxDim; use
+// NewSobolWithDirections with the numbers read by ReadJoeKuo there.
+func NewSobol(dim int) (*Sobol, error) {
+	if dim < 1 || dim > SobolMaxDim {
+		return nil, ErrDimension
+	}
+	return NewSobolWithDirections(dim, builtinDirections(dim))
+}
+
+// NewSobolWithDirections returns the Sobol sequence of dimension dim,
+// without randomization, with the given direction numbers, which must
+// cover dimensions 2 to dim.
+func NewSobolWithDirections(dim int, dirs *SobolDirections) (*Sobol, error) {
+	if dim < 1 || dim > dirs.Dim() {
+		return nil, ErrDimension
+	}
+	s := &Sobol{dim: dim, v: make([][sobolBits]uint32, dim), x: make([]uint32, dim)}
+	for k := 0; k < sobolBits; k++ {
+		s.v[0][k] = 1 << (sobolBits - 1 - k)
+	}
+	for j := 1; j < dim; j++ {
+		deg, a, m := int(dirs.s[j-1]), dirs.a[j-1], dirs.m[j-1]
+		v := &s.v[j]
+		for k := 0; k < deg && k < sobolBits; k++ {
+			v[k] = m[k] << (sobolBits - 1 - k)
+		}
+		for k := deg; k < sobolBits; k++ {
+			v[k] = v[k-deg] ^ v[k-deg]>>deg
+			for i := 1; i < deg; i++ {
+				if a>>(deg-1-i)&1 != 0 {
+					v[k] ^= v[k-i]
+				}
+			}
+		}
+	}
+	s.sv = s.v
+	return s, nil
+}
+
+// Dim returns the dimension of the points.
+func (s *Sobol) Dim() int {
+	return s.dim
+}
+
+// SetRandomization sets the randomization method and its stream, and
+// draws a randomization from the beginning of the current substream of g,
+// which may be nil for NoScrambling. The sequence restarts at its first
+// point. Drawing a randomization makes dim calls to RandU01 for
+// DigitalShift, 33 dim calls for LinearMatrixScrambling (32 per matrix,
+// one per shift) and 2 dim calls for OwenScrambling.
+func (s *Sobol) SetRandomization(g *RngStream, method Scrambling) {
+	if g == nil && method != NoScrambling {
+		panic(paramError("sobol: randomization needs a stream"))
+	}
+	s.g, s.method = g, method
+	s.randomize()
+}
+
+func (s *Sobol) randomize() {
+	s.sv = s.v
+	s.shift, s.seed = nil, nil
+	if s.g != nil {
+		s.g.ResetStartSubstream()
+	}
+	switch s.method {
+	case DigitalShift:
+		s.shift = make([]uint32, s.dim)
+		for j := range s.shift {
+			s.shift[j] = randomWord(s.g)
+		}
+	case LinearMatrixScrambling:
+		s.sv = make([][sobolBits]uint32, s.dim)
+		s.shift = make([]uint32, s.dim)
+		for j := 0; j < s.dim; j++ {
+			// row i of L acts on the bits above bit i, with a unit diagonal
+			var rows [sobolBits]uint32
+			for i := range rows {
+				above := ^uint32(0) << (sobolBits - i) // zero for i = 0
+				rows[i] = randomWord(s.g)&above | 1<<(sobolBits-1-i)
+			}
+			for k, v := range s.v[j] {
+				var w uint32
+				for i, r := range rows {
+					w |= uint32(bits.OnesCount32(r&v)&1) << (sobolBits - 1 - i)
+				}
+				s.sv[j][k] = w
+			}
+			s.shift[j] = randomWord(s.g)
+		}
+	case OwenScrambling:
+		s.seed = make([]uint64, s.dim)
+		for j := range s.seed {
+			s.seed[j] = uint64(randomWord(s.g))<<32 | uint64(randomWord(s.g))
+		}
+	}
+	s.SkipTo(0)
+}
+
+// randomWord returns 32 random bits from one call to RandU01.
+func randomWord(g *RngStream) uint32 {
+	return uint32(g.RandU01() * (1 << 32))
+}
+
+// owen returns the nested uniform scramble of x with the given seed.
+func owen(x uint32, seed uint64) uint32 {
+	var y uint32
+	for i := 0; i < sobolBits; i++ {
+		// prefix: the i bits above bit i (none for i = 0), tagged with i
+		prefix := uint64(x>>(sobolBits-i)) << 6
+		flip := uint32(splitMix64(seed^splitMix64(prefix|uint64(i))) >> 63)
+		y |= (x>>(sobolBits-1-i)&1 ^ flip) << (sobolBits - 1 - i)
+	}
+	return y
+}
+
+// Next writes the next point into dst, which must have length at least
+// Dim(). It panics after 2^32 points.
+func (s *Sobol) Next(dst []float64) {
+	if len(dst) < s.dim {
+		panic(ErrDimension)
+	}
+	if s.index>>sobolBits != 0 {
+		panic(paramError("sobol: sequence exhausted after 2^%d points", sobolBits))
+	}
+	if s.index > 0 {
+		c := bits.TrailingZeros64(s.index)
+		for j := range s.x {
+			s.x[j] ^= s.sv[j][c]
+		}
+	}
+	s.index++
+	for j, x := range s.x {
+		switch s.method {
+		case DigitalShift, LinearMatrixScrambling:
+			x ^= s.shift[j]
+		case OwenScrambling:
+			x = owen(x, s.seed[j])
+		}
+		dst[j] = (float64(x) + 0.5) / (1 << sobolBits)
+	}
+}
+
+// SkipTo moves the sequence to its point of index n, the first being 0,
+// keeping the randomization.
+func (s *Sobol) SkipTo(n uint64) {
+	if n > 1<<sobolBits {
+		panic(paramError("sobol: cannot skip beyond 2^%d points", sobolBits))
+	}
+	s.index = n
+	for j := range s.x {
+		s.x[j] = 0
+	}
+	if n == 0 {
+		return
+	}
+	// x holds the point before n, in Gray code order; Next applies the
+	// step from n-1 to n
+	gray := (n - 1) ^ (n-1)>>1
+	for k := 0; gray != 0; k, gray = k+1, gray>>1 {
+		if gray&1 != 0 {
+			for j := range s.x {
+				s.x[j] ^= s.sv[j][k]
+			}
+		}
+	}
+}
+
+// Index returns the index of the next point.
+func (s *Sobol) Index() uint64 {
+	return s.index
+}
+
+// ResetStartStream restarts the sequence at its first point, with the
+// randomization drawn from the beginning of the stream.
+func (s *Sobol) ResetStartStream() {
+	if s.g != nil {
+		s.g.ResetStartStream()
+	}
+	s.randomize()
+}
+
+// ResetStartSubstream restarts the sequence at its first point, with the
+// same randomization.
+func (s *Sobol) ResetStartSubstream() {
+	s.SkipTo(0)
+}
+
+// ResetNextSubstream restarts the sequence at its first point, with a new
+// randomization drawn from the next substream of the stream.
+func (s *Sobol) ResetNextSubstream() {
+	if s.g != nil {
+		s.g.ResetNextSubstream()
+	}
+	s.randomize()
+}
@@ -0,0 +1,262 @@
+package rngstream
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// isNet reports whether the 2^m points x have exactly one point in every
+// box of size 2^-a by 2^-(m-a) in dimensions i and j, for a = 0, ..., m:
+// the (0,m,2)-net property of the projection. If i = j, it checks that
+// the coordinate alone has one point in each interval of length 2^-m.
+func isNet(x [][]float64, i, j, m int) bool {
+	n := 1 << m
+	for a := 0; a <= m; a++ {
+		if i == j && a != m {
+			continue
+		}
+		seen := make(map[[2]int]bool)
+		for _, p := range x {
+			cell := [2]int{int(p[i] * float64(int(1)<<a)), 0}
+			if i != j {
+				cell[1] = int(p[j] * float64(int(1)<<(m-a)))
+			}
+			if seen[cell] {
+				return false
+			}
+			seen[cell] = true
+		}
+		if len(seen) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// tValue returns the smallest t for which the 2^m points x form a
+// (t,m,2)-net in the projection on dimensions i and j: every box of size
+// 2^-a by 2^-(m-t-a) holds exactly 2^t points.
+func tValue(x [][]float64, i, j, m int) int {
+	for t := 0; t < m; t++ {
+		ok := true
+		for a := 0; a <= m-t && ok; a++ {
+			count := make([]int, 1<<(m-t))
+			for _, p := range x {
+				c := int(p[i]*float64(int(1)<<a))<<(m-t-a) | int(p[j]*float64(int(1)<<(m-t-a)))
+				if count[c]++; count[c] > 1<<t {
+					ok = false
+					break
+				}
+			}
+		}
+		if ok {
+			return t
+		}
+	}
+	return m
+}
+
+func sobolPoints(s *Sobol, n int) [][]float64 {
+	x := newMatrix(n, s.Dim())
+	for i := range x {
+		s.Next(x[i])
+	}
+	return x
+}
+
+func TestSobolPolynomials(t *testing.T) {
+	// degree and inner coefficients for dimensions 2 to 21 from the file
+	// new-joe-kuo-6.21201
+	want := [][2]int{{1, 0}, {2, 1}, {3, 1}, {3, 2}, {4, 1}, {4, 4},
+		{5, 2}, {5, 4}, {5, 7}, {5, 11}, {5, 13}, {5, 14},
+		{6, 1}, {6, 13}, {6, 16}, {6, 19}, {6, 22}, {6, 25}, {7, 1}, {7, 4}}
+	dirs := builtinDirections(21)
+	for i, w := range want {
+		if int(dirs.s[i]) != w[0] || int(dirs.a[i]) != w[1] {
+			t.Errorf("dimension %d: s = %d, a = %d, wanted %v", i+2, dirs.s[i], dirs.a[i], w)
+		}
+	}
+	// there are 60 primitive polynomials of degree 10
+	polys := sobolPolynomials(1200)
+	count := 0
+	for _, p := range polys {
+		if p>>10 == 1 {
+			count++
+		}
+	}
+	if count != 60 {
+		t.Errorf("%d primitive polynomials of degree 10, wanted 60", count)
+	}
+}
+
+func TestSobol(t *testing.T) {
+	s, err := NewSobol(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first points, in Gray code order
+	want := [][]float64{{0, 0, 0}, {0.5, 0.5, 0.5}, {0.75, 0.25, 0.25},
+		{0.25, 0.75, 0.75}, {0.375, 0.375, 0.625}, {0.875, 0.875, 0.125}}
+	p := make([]float64, 3)
+	for i, w := range want {
+		s.Next(p)
+		for j := range w {
+			if math.Abs(p[j]-w[j]) > 1e-9 {
+				t.Errorf("point %d = %v, wanted %v", i, p, w)
+				break
+			}
+		}
+	}
+
+	// net properties of the first 2^8 points in all dimensions
+	s, _ = NewSobol(SobolMaxDim)
+	x := sobolPoints(s, 256)
+	for j := 0; j < SobolMaxDim; j++ {
+		if !isNet(x, j, j, 8) {
+			t.Errorf("coordinate %d is not stratified", j)
+		}
+	}
+	if !isNet(x, 0, 1, 8) {
+		t.Errorf("projection (0, 1) is not a (0,8,2)-net")
+	}
+
+	// skipping ahead gives the same points
+	for _, n := range []uint64{0, 1, 5, 100, 255} {
+		s.SkipTo(n)
+		y := make([]float64, SobolMaxDim)
+		s.Next(y)
+		for j := range y {
+			if y[j] != x[n][j] {
+				t.Errorf("SkipTo(%d): coordinate %d is %v, wanted %v", n, j, y[j], x[n][j])
+				break
+			}
+		}
+	}
+	if s.Index() != 256 {
+		t.Errorf("Index = %d, wanted 256", s.Index())
+	}
+
+	// the t-values of all two-dimensional projections are within the
+	// bound (e_i - 1) + (e_j - 1), with e_i the degree of the polynomial
+	// of dimension i (1 for the first)
+	dirs := builtinDirections(SobolMaxDim)
+	degree := func(j int) int {
+		if j == 0 {
+			return 1
+		}
+		return int(dirs.s[j-1])
+	}
+	s.SkipTo(0)
+	x = sobolPoints(s, 1<<12)
+	for i := 0; i < SobolMaxDim; i++ {
+		for j := i + 1; j < SobolMaxDim; j++ {
+			if tv, bound := tValue(x, i, j, 12), degree(i)+degree(j)-2; tv > bound {
+				t.Errorf("projection (%d, %d) has t-value %d, bound %d", i, j, tv, bound)
+			}
+		}
+	}
+	if _, err := NewSobol(SobolMaxDim + 1); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
+
+func TestSobolRandomization(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("sobol")
+	s, _ := NewSobol(5)
+	for _, method := range []Scrambling{DigitalShift, LinearMatrixScrambling, OwenScrambling} {
+		g.ResetStartStream()
+		s.SetRandomization(g, method)
+		x := sobolPoints(s, 256)
+		for j := 0; j < 5; j++ {
+			if !isNet(x, j, j, 8) {
+				t.Errorf("method %d: coordinate %d is not stratified", method, j)
+			}
+		}
+		if !isNet(x, 0, 1, 8) {
+			t.Errorf("method %d: projection (0, 1) is not a (0,8,2)-net", method)
+		}
+
+		// same randomization after ResetStartSubstream, new one after
+		// ResetNextSubstream, the first again after ResetStartStream
+		y := make([]float64, 5)
+		s.ResetStartSubstream()
+		s.Next(y)
+		if y[0] != x[0][0] {
+			t.Errorf("method %d: ResetStartSubstream changed the randomization", method)
+		}
+		s.ResetNextSubstream()
+		s.Next(y)
+		if y[0] == x[0][0] {
+			t.Errorf("method %d: ResetNextSubstream kept the randomization", method)
+		}
+		s.ResetStartStream()
+		s.Next(y)
+		if y[0] != x[0][0] {
+			t.Errorf("method %d: ResetStartStream did not restore the randomization", method)
+		}
+
+		// unbiased: the product of the coordinates has mean 1/32
+		var sum, sq float64
+		const reps = 50
+		for r := 0; r < reps; r++ {
+			s.ResetNextSubstream()
+			est := 0.0
+			for i := 0; i < 256; i++ {
+				s.Next(y)
+				est += y[0] * y[1] * y[2] * y[3] * y[4]
+			}
+			est /= 256
+			sum += est
+			sq += est * est
+		}
+		m := sum / reps
+		se := math.Sqrt((sq/reps - m*m) / (reps - 1))
+		if math.Abs(m-1.0/32) > 4*se+1e-12 {
+			t.Errorf("method %d: mean %v +- %v, wanted 1/32", method, m, se)
+		}
+	}
+}
+
+func TestReadJoeKuo(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("d       s       a       m_i\n")
+	dirs := builtinDirections(21)
+	for i := range dirs.s {
+		fmt.Fprintf(&b, "%d %d %d", i+2, dirs.s[i], dirs.a[i])
+		for _, m := range dirs.m[i] {
+			fmt.Fprintf(&b, " %d", m)
+		}
+		b.WriteString("\n")
+	}
+	read, err := ReadJoeKuo(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Dim() != 21 {
+		t.Fatalf("read %d dimensions, wanted 21", read.Dim())
+	}
+	s1, _ := NewSobol(21)
+	s2, err := NewSobolWithDirections(21, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, p2 := make([]float64, 21), make([]float64, 21)
+	for i := 0; i < 100; i++ {
+		s1.Next(p1)
+		s2.Next(p2)
+		for j := range p1 {
+			if p1[j] != p2[j] {
+				t.Fatalf("point %d differs", i)
+			}
+		}
+	}
+	if _, err := NewSobolWithDirections(22, read); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+	if _, err := ReadJoeKuo(strings.NewReader("header\n2 1 0 2\n")); err == nil {
+		t.Errorf("expected error for an even m")
+	}
+}
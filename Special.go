@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Special functions needed by the distribution functions. The continued
+// fractions are evaluated with the modified Lentz method.
+
+const (
+	specialEps  = 1e-15
+	specialTiny = 1e-300
+	specialIter = 1000
+)
+
+// regIncGammaP returns the regularized lower incomplete gamma function
+// P(a, x) = gamma(a, x) / Gamma(a), for a > 0.
+func regIncGammaP(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if math.IsInf(x, 1) {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lga)
+	if x < a+1 {
+		// series representation
+		ap := a
+		del := 1 / a
+		sum := del
+		for n := 0; n < specialIter; n++ {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*specialEps {
+				break
+			}
+		}
+		return sum * front
+	}
+	// continued fraction for Q(a, x)
+	b := x + 1 - a
+	c := 1 / specialTiny
+	d := 1 / b
+	h := d
+	for i := 1; i < specialIter; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < specialTiny {
+			d = specialTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < specialTiny {
+			c = specialTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < specialEps {
+			break
+		}
+	}
+	return 1 - front*h
+}
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b),
+// for a, b > 0 and 0 <= x <= 1.
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lab, _ := math.Lgamma(a + b)
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaCF(a, b, x) / a
+	}
+	return 1 - front*betaCF(b, a, 1-x)/b
+}
+
+// betaCF evaluates the continued fraction for the incomplete beta function.
+func betaCF(a, b, x float64) float64 {
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < specialTiny {
+		d = specialTiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m < specialIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < specialTiny {
+			d = specialTiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < specialTiny {
+			c = specialTiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < specialTiny {
+			d = specialTiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < specialTiny {
+			c = specialTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < specialEps {
+			break
+		}
+	}
+	return h
+}
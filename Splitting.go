@@ -0,0 +1,290 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"math"
+	"sort"
+)
+
+// Trajectory is the state of one trajectory of a model simulated by
+// splitting.
+type Trajectory interface {
+	// Clone returns a copy of the state that shares nothing mutable
+	// with it.
+	Clone() Trajectory
+}
+
+// SplittingModel describes the Markov model of a splitting simulation.
+// Initial returns a new trajectory, drawing its random inputs from g.
+// Importance maps a state to the real line, where the levels are set.
+// Step advances the trajectory x by one step, in place, drawing from g,
+// and returns false once it has ended without reaching the rare set, for
+// instance on returning to its regeneration set or at its time horizon;
+// every trajectory must end or reach the rare set.
+type SplittingModel struct {
+	Initial    func(g *RngStream) Trajectory
+	Importance func(x Trajectory) float64
+	Step       func(x Trajectory, g *RngStream) bool
+}
+
+// Splitting estimates the probability that a trajectory reaches the rare
+// set {x : Importance(x) >= levels[m-1]} before it ends, by multilevel
+// splitting on the thresholds levels[0] < ... < levels[m-1], either with
+// a fixed effort per level or by RESTART.
+//
+// Every trajectory, initial or cloned, draws from its own stream, a copy
+// of g positioned at a distinct substream, so that clones of the same
+// state diverge, and the whole simulation is reproducible: substream 0
+// of g (counting from the start of the stream) drives the engine's own
+// choices, and substreams 1, 2, ... are handed to the trajectories in the
+// order they are created. Successive estimations continue the numbering,
+// and are thus independent; ResetStartStream restarts it.
+type Splitting struct {
+	g      *RngStream
+	levels []float64
+	model  SplittingModel
+	ctl    *RngStream // engine choices
+	cursor *RngStream // at the substream of the next trajectory
+}
+
+// SplittingResult holds the outcome of a splitting estimation.
+type SplittingResult struct {
+	// Estimates holds independent unbiased estimates of the probability:
+	// one per run for FixedEffort, one per root trajectory for Restart.
+	Estimates []float64
+	Mean      float64 // estimate of the probability
+	Variance  float64 // sample variance of Estimates
+	StdError  float64 // standard error of Mean; StdError^2 estimates its variance
+
+	// LevelProbabilities holds, for FixedEffort, the pooled fractions of
+	// the trajectories started at each level that reached the next one.
+	LevelProbabilities []float64
+	Trajectories       int // trajectories simulated, clones included
+	Steps              int // calls to Step
+}
+
+// NewSplitting returns a splitting engine for model on the given
+// strictly increasing thresholds, drawing its streams from g.
+func NewSplitting(g *RngStream, levels []float64, model SplittingModel) (*Splitting, error) {
+	if len(levels) == 0 {
+		return nil, ErrDimension
+	}
+	for i := 1; i < len(levels); i++ {
+		if !(levels[i] > levels[i-1]) {
+			return nil, paramError("splitting: levels must be strictly increasing")
+		}
+	}
+	if model.Initial == nil || model.Importance == nil || model.Step == nil {
+		return nil, paramError("splitting: the model needs Initial, Importance and Step")
+	}
+	s := &Splitting{g: g, levels: append([]float64(nil), levels...), model: model}
+	s.ResetStartStream()
+	return s, nil
+}
+
+// ResetStartStream restarts the numbering of the substreams, so that the
+// next estimation repeats the first one.
+func (s *Splitting) ResetStartStream() {
+	ctl, cursor := *s.g, *s.g
+	ctl.resetSubstream(0)
+	cursor.resetSubstream(1)
+	s.ctl, s.cursor = &ctl, &cursor
+}
+
+// stream returns the stream of a new trajectory.
+func (s *Splitting) stream() *RngStream {
+	c := *s.cursor
+	s.cursor.ResetNextSubstream()
+	return &c
+}
+
+// level returns the number of thresholds at or below the importance of x.
+func (s *Splitting) level(x Trajectory) int {
+	v := s.model.Importance(x)
+	return sort.Search(len(s.levels), func(i int) bool { return s.levels[i] > v })
+}
+
+// FixedEffort runs the fixed-effort splitting estimator runs >= 2 times,
+// independently. In each run, effort[0] initial trajectories are
+// simulated until they reach levels[0] or end; then, for k = 1, ..., m-1,
+// effort[k] trajectories are started from clones of the states in which
+// trajectories first reached levels[k-1], spread as evenly as possible
+// among them (the remainder going to states picked at random), and
+// simulated until they reach levels[k] or end. The estimate of the run is
+// the product of the fractions of trajectories reaching the next level,
+// and is unbiased. A run stops early, with estimate 0, if no trajectory
+// reaches a level.
+func (s *Splitting) FixedEffort(effort []int, runs int) (*SplittingResult, error) {
+	m := len(s.levels)
+	if len(effort) != m {
+		return nil, ErrDimension
+	}
+	for _, n := range effort {
+		if n < 1 {
+			return nil, paramError("splitting: need an effort >= 1 per level")
+		}
+	}
+	if runs < 2 {
+		return nil, paramError("splitting: need runs >= 2")
+	}
+	res := &SplittingResult{Estimates: make([]float64, runs), LevelProbabilities: make([]float64, m)}
+	started, reached := make([]int, m), make([]int, m)
+	for r := range res.Estimates {
+		est := 1.0
+		var entrance []Trajectory
+		for k := 0; k < m && est > 0; k++ {
+			var next []Trajectory
+			for _, from := range s.assign(effort[k], len(entrance)) {
+				var x Trajectory
+				g := s.stream()
+				if k == 0 {
+					x = s.model.Initial(g)
+				} else {
+					x = entrance[from].Clone()
+				}
+				res.Trajectories++
+				if s.runTo(x, g, k+1, res) {
+					next = append(next, x)
+				}
+			}
+			started[k] += effort[k]
+			reached[k] += len(next)
+			est *= float64(len(next)) / float64(effort[k])
+			entrance = next
+		}
+		res.Estimates[r] = est
+	}
+	for k := range res.LevelProbabilities {
+		if started[k] > 0 {
+			res.LevelProbabilities[k] = float64(reached[k]) / float64(started[k])
+		}
+	}
+	res.summarize()
+	return res, nil
+}
+
+// assign returns the entrance state of each of the n trajectories of a
+// stage, among h states: n/h each, and one more for n mod h of them, in
+// random order drawn from the engine stream. With h = 0 (the first
+// stage), it returns n zeros.
+func (s *Splitting) assign(n, h int) []int {
+	from := make([]int, n)
+	if h == 0 {
+		return from
+	}
+	perm := make([]int, h)
+	for i := range perm {
+		perm[i] = i
+	}
+	// partial Fisher-Yates: the first n mod h states get one more
+	extra := n % h
+	for i := 0; i < extra; i++ {
+		j := s.ctl.RandInt(i, h-1)
+		perm[i], perm[j] = perm[j], perm[i]
+	}
+	i := 0
+	for k := 0; k < h; k++ {
+		for c := 0; c < n/h; c++ {
+			from[i] = k
+			i++
+		}
+	}
+	for k := 0; k < extra; k++ {
+		from[i] = perm[k]
+		i++
+	}
+	return from
+}
+
+// runTo simulates x until it reaches level index target (importance at
+// or above levels[target-1]), which it reports, or ends.
+func (s *Splitting) runTo(x Trajectory, g *RngStream, target int, res *SplittingResult) bool {
+	for s.level(x) < target {
+		res.Steps++
+		if !s.model.Step(x, g) {
+			return false
+		}
+	}
+	return true
+}
+
+// restartTrajectory is a trajectory of a RESTART simulation, with the
+// level index it was born at (0 for a root) and its level index when
+// last checked, above which up-crossings make it split.
+type restartTrajectory struct {
+	x     Trajectory
+	g     *RngStream
+	birth int
+	level int
+}
+
+// Restart runs n >= 2 independent root trajectories with the RESTART
+// method of Villén-Altamirano. Whenever a trajectory up-crosses
+// levels[k-1], k = 1, ..., m-1, factor[k-1] - 1 retrials are cloned from
+// its state, each on a new stream; a retrial is killed when it
+// down-crosses the level it was born at, while root trajectories
+// continue until they end. Every trajectory stops on reaching the rare
+// set. The estimate of a root is the number of hits of the rare set by
+// it and its descendants divided by the product of the factors, and is
+// unbiased; factors near the inverse of the probability of reaching the
+// next level are the most efficient.
+func (s *Splitting) Restart(factor []int, n int) (*SplittingResult, error) {
+	m := len(s.levels)
+	if len(factor) != m-1 {
+		return nil, ErrDimension
+	}
+	weight := 1.0
+	for _, f := range factor {
+		if f < 1 {
+			return nil, paramError("splitting: need splitting factors >= 1")
+		}
+		weight /= float64(f)
+	}
+	if n < 2 {
+		return nil, paramError("splitting: need n >= 2 root trajectories")
+	}
+	res := &SplittingResult{Estimates: make([]float64, n)}
+	for r := range res.Estimates {
+		g := s.stream()
+		x := s.model.Initial(g)
+		res.Trajectories++
+		stack := []restartTrajectory{{x: x, g: g}}
+		hits := 0
+		for len(stack) > 0 {
+			t := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			l := s.level(t.x)
+			for {
+				// split at every level crossed upwards: a retrial is born
+				// at the lowest one, and splits in turn at those above
+				for k := t.level + 1; k <= l && k < m; k++ {
+					for i := 1; i < factor[k-1]; i++ {
+						stack = append(stack, restartTrajectory{x: t.x.Clone(), g: s.stream(), birth: k, level: k})
+						res.Trajectories++
+					}
+				}
+				t.level = l
+				if l >= m {
+					hits++
+					break
+				}
+				res.Steps++
+				if !s.model.Step(t.x, t.g) {
+					break
+				}
+				if l = s.level(t.x); l < t.birth {
+					break
+				}
+			}
+		}
+		res.Estimates[r] = float64(hits) * weight
+	}
+	res.summarize()
+	return res, nil
+}
+
+func (res *SplittingResult) summarize() {
+	res.Mean, res.Variance = meanVariance(res.Estimates)
+	res.StdError = math.Sqrt(res.Variance / float64(len(res.Estimates)))
+}
@@ -0,0 +1,127 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// walk is a random walk on the integers, up with probability p.
+type walk struct {
+	x int
+	p float64
+}
+
+func (w *walk) Clone() Trajectory {
+	c := *w
+	return &c
+}
+
+// ruinModel is the walk started at 1 and ending at 0, whose probability
+// of reaching n first is (1 - r) / (1 - r^n), r = (1-p)/p.
+func ruinModel(p float64) SplittingModel {
+	return SplittingModel{
+		Initial:    func(g *RngStream) Trajectory { return &walk{x: 1, p: p} },
+		Importance: func(x Trajectory) float64 { return float64(x.(*walk).x) },
+		Step: func(x Trajectory, g *RngStream) bool {
+			w := x.(*walk)
+			if g.RandU01() < w.p {
+				w.x++
+			} else {
+				w.x--
+			}
+			return w.x > 0
+		},
+	}
+}
+
+func TestSplitting(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	const p, top = 0.3, 20
+	r := (1 - p) / p
+	exact := (1 - r) / (1 - math.Pow(r, top))
+
+	levels := []float64{3, 5, 7, 9, 11, 13, 15, 17, 19, 20}
+	s, err := NewSplitting(New("splitting"), levels, ruinModel(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	effort := make([]int, len(levels))
+	for k := range effort {
+		effort[k] = 500
+	}
+	fe, err := s.FixedEffort(effort, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(fe.Mean-exact) > 4*fe.StdError || fe.StdError > 0.1*exact {
+		t.Errorf("fixed effort: %v +- %v, wanted %v", fe.Mean, fe.StdError, exact)
+	}
+	// from 19 the walk reaches 20 before 0 with probability
+	// (1 - r^19) / (1 - r^20)
+	want := (1 - math.Pow(r, top-1)) / (1 - math.Pow(r, top))
+	if q := fe.LevelProbabilities[len(levels)-1]; math.Abs(q-want) > 0.03 {
+		t.Errorf("last level probability %v, wanted %v", q, want)
+	}
+
+	// the same estimate after ResetStartStream, a new one after that
+	s.ResetStartStream()
+	again, _ := s.FixedEffort(effort, 20)
+	if again.Mean != fe.Mean {
+		t.Errorf("repeated estimate %v, wanted %v", again.Mean, fe.Mean)
+	}
+	other, _ := s.FixedEffort(effort, 20)
+	if other.Mean == fe.Mean {
+		t.Errorf("independent estimate repeats %v", fe.Mean)
+	}
+
+	// RESTART with factors near the inverse of the level probabilities
+	factor := make([]int, len(levels)-1)
+	for k := range factor {
+		factor[k] = 5
+	}
+	factor[0] = 8
+	rs, err := s.Restart(factor, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(rs.Mean-exact) > 4*rs.StdError || rs.StdError > 0.1*exact {
+		t.Errorf("RESTART: %v +- %v, wanted %v", rs.Mean, rs.StdError, exact)
+	}
+
+	// crossing several levels in one step splits at each of them: a walk
+	// going up by 2 crosses two levels at a time, and RESTART must agree
+	// with plain Monte Carlo
+	jump := ruinModel(0.4)
+	jump.Step = func(x Trajectory, g *RngStream) bool {
+		w := x.(*walk)
+		if g.RandU01() < w.p {
+			w.x += 2
+		} else {
+			w.x--
+		}
+		return w.x > 0
+	}
+	s, _ = NewSplitting(New("jump"), []float64{2, 3, 4, 5, 6}, jump)
+	mc := 0.0
+	g := New("mc")
+	for i := 0; i < 200000; i++ {
+		w := jump.Initial(g)
+		for jump.Importance(w) < 6 && jump.Step(w, g) {
+		}
+		if jump.Importance(w) >= 6 {
+			mc++
+		}
+	}
+	mc /= 200000
+	rs, _ = s.Restart([]int{2, 2, 2, 2}, 20000)
+	if math.Abs(rs.Mean-mc) > 4*math.Hypot(rs.StdError, math.Sqrt(mc*(1-mc)/200000)) {
+		t.Errorf("RESTART with jumps: %v +- %v, Monte Carlo gives %v", rs.Mean, rs.StdError, mc)
+	}
+
+	if _, err := NewSplitting(New("bad"), []float64{2, 2}, jump); err == nil {
+		t.Errorf("expected error for levels not strictly increasing")
+	}
+	if _, err := s.Restart([]int{2}, 100); err != ErrDimension {
+		t.Errorf("expected ErrDimension, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"math"
+	"sort"
+)
+
+// Stratum is one stratum of a stratified estimator: its probability Prob
+// under the sampling distribution, and Sample, which draws one
+// observation of the response conditional on the stratum from the given
+// stream. StdDev is the conditional standard deviation of the response,
+// used by the Neyman allocation only.
+type Stratum struct {
+	Prob   float64
+	Sample func(g *RngStream) float64
+	StdDev float64
+}
+
+// Allocation is a rule distributing the sample size among strata.
+type Allocation int
+
+const (
+	// Proportional allocates samples in proportion to the stratum
+	// probabilities.
+	Proportional Allocation = iota
+	// Neyman allocates samples in proportion to Prob * StdDev, which
+	// minimizes the variance for known standard deviations.
+	Neyman
+	// AdaptivePilot draws a pilot sample in every stratum, then allocates
+	// the rest as Neyman with the standard deviations estimated so far.
+	AdaptivePilot
+)
+
+// StratifiedSampler estimates the mean of a response by stratified
+// sampling. Stratum k draws from its own copy of the stream, positioned
+// at the beginning of substream k of g (counting from the start of g),
+// so that adding samples to one stratum does not perturb the others;
+// g itself is not modified.
+type StratifiedSampler struct {
+	strata  []Stratum
+	streams []*RngStream
+	alloc   Allocation
+	pilot   int
+
+	n    []int // observations per stratum
+	mean []float64
+	m2   []float64 // sums of squared deviations (Welford)
+}
+
+// NewStratifiedSampler returns a stratified sampler for the given strata,
+// whose probabilities must be non-negative and sum to one, with the given
+// allocation rule. The Neyman rule needs StdDev >= 0 for every stratum,
+// and positive for at least one with a positive probability.
+func NewStratifiedSampler(g *RngStream, strata []Stratum, alloc Allocation) (*StratifiedSampler, error) {
+	if len(strata) == 0 {
+		return nil, ErrDimension
+	}
+	sum, sd := 0.0, 0.0
+	for _, s := range strata {
+		if !(s.Prob >= 0) || s.Sample == nil {
+			return nil, paramError("stratified: need probabilities >= 0 and a sampler per stratum")
+		}
+		if !(s.StdDev >= 0) && alloc == Neyman {
+			return nil, paramError("stratified: Neyman allocation needs standard deviations >= 0")
+		}
+		sum += s.Prob
+		sd += s.Prob * s.StdDev
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		return nil, paramError("stratified: probabilities sum to %v, not 1", sum)
+	}
+	if alloc == Neyman && !(sd > 0) {
+		return nil, paramError("stratified: Neyman allocation needs a positive standard deviation")
+	}
+	if alloc < Proportional || alloc > AdaptivePilot {
+		return nil, paramError("stratified: unknown allocation %d", alloc)
+	}
+	s := &StratifiedSampler{
+		strata:  append([]Stratum(nil), strata...),
+		streams: make([]*RngStream, len(strata)),
+		alloc:   alloc,
+		pilot:   10,
+		n:       make([]int, len(strata)),
+		mean:    make([]float64, len(strata)),
+		m2:      make([]float64, len(strata)),
+	}
+	for k := range s.streams {
+		c := *g
+		c.resetSubstream(int64(k))
+		s.streams[k] = &c
+	}
+	return s, nil
+}
+
+// SetPilot sets the pilot sample size per stratum of the AdaptivePilot
+// rule, 10 by default; values below 2 are treated as 2.
+func (s *StratifiedSampler) SetPilot(n int) {
+	if n < 2 {
+		n = 2
+	}
+	s.pilot = n
+}
+
+// Add draws m more observations in stratum k.
+func (s *StratifiedSampler) Add(k, m int) {
+	for i := 0; i < m; i++ {
+		y := s.strata[k].Sample(s.streams[k])
+		s.n[k]++
+		d := y - s.mean[k]
+		s.mean[k] += d / float64(s.n[k])
+		s.m2[k] += d * (y - s.mean[k])
+	}
+}
+
+// Run draws n more observations, distributed among the strata by the
+// allocation rule, and returns the updated estimate and its variance.
+// Every stratum with a positive probability first gets at least two
+// observations (the pilot size for AdaptivePilot), so that its variance
+// can be estimated; these count towards n. The allocation aims at the
+// optimal split of the total sample size, taking the observations
+// already drawn into account.
+func (s *StratifiedSampler) Run(n int) (mean, variance float64) {
+	least := 2
+	if s.alloc == AdaptivePilot {
+		least = s.pilot
+	}
+	for k, st := range s.strata {
+		if st.Prob > 0 && s.n[k] < least && n > 0 {
+			m := least - s.n[k]
+			if m > n {
+				m = n
+			}
+			s.Add(k, m)
+			n -= m
+		}
+	}
+	if n > 0 {
+		for k, m := range s.allocate(n) {
+			s.Add(k, m)
+		}
+	}
+	return s.Estimate()
+}
+
+// allocate splits n among the strata, in proportion to how far each is
+// below its target share of the total sample size.
+func (s *StratifiedSampler) allocate(n int) []int {
+	w := make([]float64, len(s.strata))
+	total, have := 0.0, 0
+	for k, st := range s.strata {
+		switch s.alloc {
+		case Proportional:
+			w[k] = st.Prob
+		case Neyman:
+			w[k] = st.Prob * st.StdDev
+		case AdaptivePilot:
+			if s.n[k] > 1 {
+				w[k] = st.Prob * math.Sqrt(s.m2[k]/float64(s.n[k]-1))
+			}
+		}
+		total += w[k]
+		have += s.n[k]
+	}
+	if !(total > 0) {
+		// all estimated deviations are zero: fall back on proportional
+		for k, st := range s.strata {
+			w[k] = st.Prob
+		}
+		total = 1
+	}
+	deficit := make([]float64, len(w))
+	sum := 0.0
+	for k := range w {
+		deficit[k] = math.Max(0, float64(have+n)*w[k]/total-float64(s.n[k]))
+		sum += deficit[k]
+	}
+	return largestRemainder(deficit, sum, n)
+}
+
+// largestRemainder rounds the shares n*x[k]/sum to integers adding up to
+// n, giving the leftover units to the largest fractional parts.
+func largestRemainder(x []float64, sum float64, n int) []int {
+	m := make([]int, len(x))
+	frac := make([]float64, len(x))
+	used := 0
+	for k, v := range x {
+		q := float64(n) * v / sum
+		m[k] = int(q)
+		frac[k] = q - float64(m[k])
+		used += m[k]
+	}
+	order := make([]int, len(x))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool { return frac[order[a]] > frac[order[b]] })
+	for i := 0; used < n; i++ {
+		m[order[i%len(order)]]++
+		used++
+	}
+	return m
+}
+
+// Estimate returns the stratified estimate sum_k Prob_k mean_k and its
+// estimated variance sum_k Prob_k^2 s_k^2 / n_k. Strata with a positive
+// probability and fewer than two observations make both NaN.
+func (s *StratifiedSampler) Estimate() (mean, variance float64) {
+	for k, st := range s.strata {
+		if st.Prob == 0 {
+			continue
+		}
+		if s.n[k] < 2 {
+			return math.NaN(), math.NaN()
+		}
+		mean += st.Prob * s.mean[k]
+		variance += st.Prob * st.Prob * s.m2[k] / float64(s.n[k]-1) / float64(s.n[k])
+	}
+	return mean, variance
+}
+
+// StratumStats returns the number of observations of stratum k, their
+// mean and their sample variance.
+func (s *StratifiedSampler) StratumStats(k int) (n int, mean, variance float64) {
+	n, mean = s.n[k], s.mean[k]
+	variance = math.NaN()
+	if n > 1 {
+		variance = s.m2[k] / float64(n-1)
+	}
+	return n, mean, variance
+}
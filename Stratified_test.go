@@ -0,0 +1,92 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// squareStrata returns the strata [k/m, (k+1)/m) of the estimation of
+// E[U^2] = 1/3, with the exact conditional standard deviations.
+func squareStrata(m int) []Stratum {
+	strata := make([]Stratum, m)
+	for k := range strata {
+		a, b := float64(k)/float64(m), float64(k+1)/float64(m)
+		m1 := (b*b*b - a*a*a) / 3 / (b - a)
+		m2 := (b*b*b*b*b - a*a*a*a*a) / 5 / (b - a)
+		strata[k] = Stratum{
+			Prob:   b - a,
+			StdDev: math.Sqrt(m2 - m1*m1),
+			Sample: func(g *RngStream) float64 {
+				u := a + (b-a)*g.RandU01()
+				return u * u
+			},
+		}
+	}
+	return strata
+}
+
+func TestStratifiedSampler(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("strata")
+	const n = 4000
+	plainVar := (1.0/5 - 1.0/9) / n
+
+	var vars [3]float64
+	for i, alloc := range []Allocation{Proportional, Neyman, AdaptivePilot} {
+		s, err := NewStratifiedSampler(g, squareStrata(8), alloc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, v := s.Run(n)
+		total := 0
+		for k := 0; k < 8; k++ {
+			c, _, _ := s.StratumStats(k)
+			total += c
+		}
+		if total != n {
+			t.Errorf("allocation %d: %d observations, wanted %d", alloc, total, n)
+		}
+		if math.Abs(m-1.0/3) > 4*math.Sqrt(v) {
+			t.Errorf("allocation %d: estimate %v +- %v, wanted 1/3", alloc, m, math.Sqrt(v))
+		}
+		if v > plainVar/10 {
+			t.Errorf("allocation %d: variance %v, plain Monte Carlo gives %v", alloc, v, plainVar)
+		}
+		vars[i] = v
+	}
+	// Neyman beats proportional; the adaptive rule comes close to Neyman
+	if !(vars[1] < vars[0]) || vars[2] > 1.2*vars[1] {
+		t.Errorf("variances proportional %v, Neyman %v, adaptive %v", vars[0], vars[1], vars[2])
+	}
+
+	// the Neyman allocation follows Prob * StdDev: the last stratum, with
+	// the largest spread, gets the most observations
+	s, _ := NewStratifiedSampler(g, squareStrata(4), Neyman)
+	s.Run(1000)
+	n0, _, _ := s.StratumStats(0)
+	n3, _, _ := s.StratumStats(3)
+	if n3 < 5*n0 {
+		t.Errorf("Neyman counts %d and %d for the first and last strata", n0, n3)
+	}
+
+	// adding samples to one stratum does not perturb the others
+	a, _ := NewStratifiedSampler(g, squareStrata(3), Proportional)
+	b, _ := NewStratifiedSampler(g, squareStrata(3), Proportional)
+	a.Add(0, 17)
+	a.Add(1, 5)
+	b.Add(1, 5)
+	_, ma, _ := a.StratumStats(1)
+	_, mb, _ := b.StratumStats(1)
+	if ma != mb {
+		t.Errorf("stratum 1 mean %v, wanted %v", ma, mb)
+	}
+	if m, _ := a.Estimate(); !math.IsNaN(m) {
+		t.Errorf("estimate %v with an incomplete stratum, wanted NaN", m)
+	}
+
+	bad := squareStrata(2)
+	bad[0].Prob = 0.7
+	if _, err := NewStratifiedSampler(g, bad, Proportional); err == nil {
+		t.Errorf("expected error for probabilities not summing to one")
+	}
+}
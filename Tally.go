@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// IntervalMethod selects how confidence intervals for a mean are built.
+type IntervalMethod int
+
+const (
+	// StudentInterval uses the Student t distribution with n-1 degrees of
+	// freedom, exact for normal observations.
+	StudentInterval IntervalMethod = iota
+	// NormalInterval uses the normal approximation, for large samples.
+	NormalInterval
+)
+
+// Tally is a statistical collector, as in the SSJ library: it accumulates
+// observations, such as the results of replications computed from
+// successive substreams, and reports their count, mean, variance,
+// extremes and confidence intervals for their mean. The mean and variance
+// are updated with Welford's method, which stays accurate when the
+// observations are large compared to their spread.
+//
+// A Tally is not safe for concurrent use; give each goroutine its own,
+// and combine them with Merge.
+type Tally struct {
+	name     string
+	n        int
+	mean, m2 float64
+	min, max float64
+}
+
+// NewTally returns an empty tally with the given name, used in reports.
+func NewTally(name string) *Tally {
+	t := &Tally{name: name}
+	t.Reset()
+	return t
+}
+
+// Name returns the name of the tally.
+func (t *Tally) Name() string {
+	return t.name
+}
+
+// Reset discards all observations.
+func (t *Tally) Reset() {
+	t.n, t.mean, t.m2 = 0, 0, 0
+	t.min, t.max = math.Inf(1), math.Inf(-1)
+}
+
+// Add records the observation x.
+func (t *Tally) Add(x float64) {
+	t.n++
+	d := x - t.mean
+	t.mean += d / float64(t.n)
+	t.m2 += d * (x - t.mean)
+	t.min = math.Min(t.min, x)
+	t.max = math.Max(t.max, x)
+}
+
+// Merge adds the observations of o to t, with the pairwise update of Chan,
+// Golub and LeVeque (1979), as if they had been added one by one. o is
+// not modified.
+func (t *Tally) Merge(o *Tally) {
+	if o.n == 0 {
+		return
+	}
+	n := t.n + o.n
+	d := o.mean - t.mean
+	t.mean += d * float64(o.n) / float64(n)
+	t.m2 += o.m2 + d*d*float64(t.n)*float64(o.n)/float64(n)
+	t.n = n
+	t.min = math.Min(t.min, o.min)
+	t.max = math.Max(t.max, o.max)
+}
+
+// Count returns the number of observations.
+func (t *Tally) Count() int {
+	return t.n
+}
+
+// Sum returns the sum of the observations.
+func (t *Tally) Sum() float64 {
+	return t.mean * float64(t.n)
+}
+
+// Mean returns the sample mean, or NaN without observations.
+func (t *Tally) Mean() float64 {
+	if t.n == 0 {
+		return math.NaN()
+	}
+	return t.mean
+}
+
+// Variance returns the unbiased sample variance, or NaN with fewer than
+// two observations.
+func (t *Tally) Variance() float64 {
+	if t.n < 2 {
+		return math.NaN()
+	}
+	return t.m2 / float64(t.n-1)
+}
+
+// StdDev returns the sample standard deviation.
+func (t *Tally) StdDev() float64 {
+	return math.Sqrt(t.Variance())
+}
+
+// StdError returns the standard error of the mean, StdDev/sqrt(n).
+func (t *Tally) StdError() float64 {
+	return math.Sqrt(t.Variance() / float64(t.n))
+}
+
+// Min returns the smallest observation, +Inf without observations.
+func (t *Tally) Min() float64 {
+	return t.min
+}
+
+// Max returns the largest observation, -Inf without observations.
+func (t *Tally) Max() float64 {
+	return t.max
+}
+
+// ConfidenceInterval returns the two-sided confidence interval of the
+// given level, 0 < level < 1, for the mean of the observations, built
+// with the given method. It is (NaN, NaN) with fewer than two
+// observations.
+func (t *Tally) ConfidenceInterval(level float64, method IntervalMethod) (low, high float64) {
+	if !(level > 0 && level < 1) {
+		panic(paramError("tally: need 0 < level < 1"))
+	}
+	df := math.Inf(1)
+	if method == StudentInterval {
+		df = float64(t.n - 1)
+	}
+	h := tHalfWidth(t.StdError(), df, level)
+	return t.Mean() - h, t.Mean() + h
+}
+
+// Report returns a formatted summary of the tally, with the confidence
+// interval of the given level and method.
+func (t *Tally) Report(level float64, method IntervalMethod) string {
+	return TallyReport(level, method, t)
+}
+
+// String returns the report of the tally with a 95% Student t interval.
+func (t *Tally) String() string {
+	return t.Report(0.95, StudentInterval)
+}
+
+// TallyReport returns a table summarizing the tallies, one per row, with
+// confidence intervals of the given level and method, so that results
+// computed from different sources are presented consistently.
+func TallyReport(level float64, method IntervalMethod, tallies ...*Tally) string {
+	kind := "student"
+	if method == NormalInterval {
+		kind = "normal"
+	}
+	width := len("tally")
+	for _, t := range tallies {
+		if len(t.name) > width {
+			width = len(t.name)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-*s %10s %12s %12s %12s %12s   %g%% confidence interval (%s)\n",
+		width, "tally", "num. obs.", "min", "max", "average", "std. dev.", 100*level, kind)
+	for _, t := range tallies {
+		low, high := t.ConfidenceInterval(level, method)
+		fmt.Fprintf(&b, "%-*s %10d %12.6g %12.6g %12.6g %12.6g   (%12.6g, %12.6g)\n",
+			width, t.name, t.n, t.min, t.max, t.Mean(), t.StdDev(), low, high)
+	}
+	return b.String()
+}
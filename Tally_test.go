@@ -0,0 +1,96 @@
+package rngstream
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTally(t *testing.T) {
+	// observations with a large offset: naive sums of squares lose all
+	// precision, Welford's updates do not
+	x := []float64{4, 7, 13, 16}
+	tally := NewTally("offset")
+	for _, v := range x {
+		tally.Add(1e9 + v)
+	}
+	if tally.Count() != 4 || tally.Mean() != 1e9+10 || math.Abs(tally.Variance()-30) > 1e-6 {
+		t.Errorf("count %d, mean %v, variance %v; wanted 4, 1e9+10, 30", tally.Count(), tally.Mean(), tally.Variance())
+	}
+	if tally.Min() != 1e9+4 || tally.Max() != 1e9+16 {
+		t.Errorf("min %v, max %v", tally.Min(), tally.Max())
+	}
+	se := math.Sqrt(30.0 / 4)
+	lo, hi := tally.ConfidenceInterval(0.95, StudentInterval)
+	if math.Abs((hi-lo)/2/se-3.182446) > 1e-5 {
+		t.Errorf("Student half-width %v standard errors, wanted t(3) quantile 3.182446", (hi-lo)/2/se)
+	}
+	lo, hi = tally.ConfidenceInterval(0.95, NormalInterval)
+	if math.Abs((hi-lo)/2/se-1.959964) > 1e-5 {
+		t.Errorf("normal half-width %v standard errors, wanted 1.959964", (hi-lo)/2/se)
+	}
+
+	empty := NewTally("empty")
+	if !math.IsNaN(empty.Mean()) || !math.IsNaN(empty.Variance()) || empty.Count() != 0 {
+		t.Errorf("empty tally: mean %v, variance %v", empty.Mean(), empty.Variance())
+	}
+	tally.Reset()
+	if tally.Count() != 0 || !math.IsInf(tally.Min(), 1) {
+		t.Errorf("Reset left %d observations", tally.Count())
+	}
+}
+
+func TestTallyMerge(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	const workers, n = 8, 10000
+	// one tally and one stream per goroutine, merged at the end
+	streams := make([]*RngStream, workers)
+	parts := make([]*Tally, workers)
+	for w := range streams {
+		streams[w] = New("worker")
+		parts[w] = NewTally("part")
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n*(w+1); i++ {
+				parts[w].Add(streams[w].RandU01())
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	merged := NewTally("merged")
+	for _, p := range parts {
+		merged.Merge(p)
+	}
+	merged.Merge(NewTally("empty"))
+	whole := NewTally("sequential")
+	for w := range streams {
+		streams[w].ResetStartStream()
+		for i := 0; i < n*(w+1); i++ {
+			whole.Add(streams[w].RandU01())
+		}
+	}
+	if merged.Count() != whole.Count() || merged.Min() != whole.Min() || merged.Max() != whole.Max() ||
+		math.Abs(merged.Mean()-whole.Mean()) > 1e-12 || math.Abs(merged.Variance()-whole.Variance()) > 1e-12 {
+		t.Errorf("merged %d, %v, %v; sequential %d, %v, %v", merged.Count(), merged.Mean(), merged.Variance(),
+			whole.Count(), whole.Mean(), whole.Variance())
+	}
+	if math.Abs(merged.Mean()-0.5) > 4*merged.StdError() || math.Abs(merged.Variance()-1.0/12) > 0.002 {
+		t.Errorf("uniform mean %v, variance %v", merged.Mean(), merged.Variance())
+	}
+
+	report := TallyReport(0.9, NormalInterval, merged, whole)
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "90% confidence interval (normal)") ||
+		!strings.HasPrefix(lines[1], "merged") || !strings.Contains(lines[2], "360000") {
+		t.Errorf("report:\n%s", report)
+	}
+	if s := merged.String(); !strings.Contains(s, "95% confidence interval (student)") {
+		t.Errorf("String:\n%s", s)
+	}
+}
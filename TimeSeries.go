@@ -0,0 +1,416 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// ARMA generates an autoregressive moving-average ARMA(p, q) process
+//
+//	X_t = c + phi_1 X_{t-1} + ... + phi_p X_{t-p}
+//	        + e_t + theta_1 e_{t-1} + ... + theta_q e_{t-q},
+//
+// with independent N(0, sigma^2) innovations e_t, driven by a stream. Each
+// innovation is generated by inversion from exactly one call to RandU01.
+//
+// The process starts at its mean and runs through a warm-up period before
+// its first value is returned, long enough for the influence of the
+// starting point to decay below 1e-12 of the stationary scale, so that the
+// output starts in stationarity. The warm-up consumes one uniform per
+// step; its length is given by Warmup and can be changed with SetWarmup.
+//
+// ResetStartSubstream and ResetNextSubstream restart the process, warm-up
+// included, together with its stream, so that each replication
+// regenerates an identical sequence from its substream.
+type ARMA struct {
+	g          *RngStream
+	c, sigma   float64
+	phi, theta []float64
+	psi        []float64 // MA(infinity) weights
+	warmup     int
+	x, e       []float64 // x[i] = X_{t-1-i}, e[i] = e_{t-1-i}
+	started    bool
+}
+
+// maxPsi bounds the number of MA(infinity) weights kept for a process
+// close to a unit root.
+const maxPsi = 1 << 20
+
+// NewAR returns the AR(p) process with constant c, coefficients phi and
+// innovation standard deviation sigma, driven by g.
+func NewAR(g *RngStream, c float64, phi []float64, sigma float64) (*ARMA, error) {
+	return NewARMA(g, c, phi, nil, sigma)
+}
+
+// NewARMA returns the ARMA(p, q) process with constant c, autoregressive
+// coefficients phi, moving-average coefficients theta and innovation
+// standard deviation sigma > 0, driven by g. The autoregressive part must
+// be stationary, that is, all roots of 1 - phi_1 z - ... - phi_p z^p must
+// lie outside the unit circle.
+func NewARMA(g *RngStream, c float64, phi, theta []float64, sigma float64) (*ARMA, error) {
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(c) || math.IsInf(c, 0) {
+		return nil, paramError("ARMA: need finite c and sigma > 0")
+	}
+	for _, v := range append(append([]float64(nil), phi...), theta...) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, paramError("ARMA: coefficients must be finite")
+		}
+	}
+	if !stationaryAR(phi) {
+		return nil, paramError("ARMA: autoregressive part is not stationary")
+	}
+	a := &ARMA{g: g, c: c, sigma: sigma,
+		phi:   append([]float64(nil), phi...),
+		theta: append([]float64(nil), theta...),
+		x:     make([]float64, len(phi)),
+		e:     make([]float64, len(theta)),
+	}
+	a.psi = psiWeights(a.phi, a.theta)
+	a.warmup = len(a.psi)
+	a.restart()
+	return a, nil
+}
+
+// stationaryAR reports whether the AR polynomial with coefficients phi is
+// stationary, by running the Levinson-Durbin recursion backwards: the
+// process is stationary iff all the partial autocorrelations it yields
+// are less than one in absolute value.
+func stationaryAR(phi []float64) bool {
+	a := append([]float64(nil), phi...)
+	for k := len(a); k > 0; k-- {
+		kappa := a[k-1]
+		if !(math.Abs(kappa) < 1) {
+			return false
+		}
+		b := make([]float64, k-1)
+		for j := range b {
+			b[j] = (a[j] + kappa*a[k-2-j]) / (1 - kappa*kappa)
+		}
+		a = b
+	}
+	return true
+}
+
+// psiWeights returns the weights psi_0 = 1, psi_1, ... of the MA(infinity)
+// representation X_t - mu = sum_j psi_j e_{t-j}, up to the point where
+// they have decayed below 1e-12 of their largest value.
+func psiWeights(phi, theta []float64) []float64 {
+	window := len(phi)
+	if window < 1 {
+		window = 1
+	}
+	psi := []float64{1}
+	big := 1.0
+	for j := 1; j < maxPsi; j++ {
+		v := 0.0
+		if j <= len(theta) {
+			v = theta[j-1]
+		}
+		for i, p := range phi {
+			if j-1-i >= 0 {
+				v += p * psi[j-1-i]
+			}
+		}
+		psi = append(psi, v)
+		big = math.Max(big, math.Abs(v))
+		if j < len(theta) || j < window {
+			continue
+		}
+		small := true
+		for _, w := range psi[j+1-window:] {
+			if math.Abs(w) > 1e-12*big {
+				small = false
+				break
+			}
+		}
+		if small {
+			break
+		}
+	}
+	return psi
+}
+
+// SetWarmup sets the number of steps run, and discarded, before the first
+// value after a restart. Negative values are treated as zero.
+func (a *ARMA) SetWarmup(n int) {
+	if n < 0 {
+		n = 0
+	}
+	a.warmup = n
+}
+
+// Warmup returns the number of warm-up steps.
+func (a *ARMA) Warmup() int {
+	return a.warmup
+}
+
+// Next returns the next value of the process. The first call after a
+// restart runs the warm-up.
+func (a *ARMA) Next() float64 {
+	if !a.started {
+		a.started = true
+		for i := 0; i < a.warmup; i++ {
+			a.step()
+		}
+	}
+	return a.step()
+}
+
+func (a *ARMA) step() float64 {
+	eps := a.sigma * StdNormalInv(a.g.RandU01())
+	x := a.c + eps
+	for i, p := range a.phi {
+		x += p * a.x[i]
+	}
+	for j, th := range a.theta {
+		x += th * a.e[j]
+	}
+	if len(a.x) > 0 {
+		copy(a.x[1:], a.x)
+		a.x[0] = x
+	}
+	if len(a.e) > 0 {
+		copy(a.e[1:], a.e)
+		a.e[0] = eps
+	}
+	return x
+}
+
+// Mean returns the stationary mean c / (1 - phi_1 - ... - phi_p).
+func (a *ARMA) Mean() float64 {
+	s := 1.0
+	for _, p := range a.phi {
+		s -= p
+	}
+	return a.c / s
+}
+
+// Variance returns the stationary variance.
+func (a *ARMA) Variance() float64 {
+	return a.Autocovariance(0)
+}
+
+// Autocovariance returns the stationary autocovariance at lag k, computed
+// from the MA(infinity) weights.
+func (a *ARMA) Autocovariance(k int) float64 {
+	if k < 0 {
+		k = -k
+	}
+	s := 0.0
+	for j := 0; j+k < len(a.psi); j++ {
+		s += a.psi[j] * a.psi[j+k]
+	}
+	return a.sigma * a.sigma * s
+}
+
+// ResetStartSubstream restarts the process and resets its stream to the
+// beginning of the current substream, so that the same sequence is
+// generated again.
+func (a *ARMA) ResetStartSubstream() {
+	a.g.ResetStartSubstream()
+	a.restart()
+}
+
+// ResetNextSubstream restarts the process and moves its stream to the
+// beginning of the next substream, for the next replication.
+func (a *ARMA) ResetNextSubstream() {
+	a.g.ResetNextSubstream()
+	a.restart()
+}
+
+func (a *ARMA) restart() {
+	m := a.Mean()
+	for i := range a.x {
+		a.x[i] = m
+	}
+	for i := range a.e {
+		a.e[i] = 0
+	}
+	a.started = false
+}
+
+// ARTA generates an autoregressive-to-anything process (Cario and Nelson,
+// 1996): a time series with a given marginal distribution and given
+// autocorrelations at lags 1, ..., p. It is obtained as
+// X_t = F^-1(Phi(Z_t)), for F the marginal distribution function, Phi the
+// standard normal one and Z_t a stationary AR(p) base process with unit
+// variance, whose autocorrelations are chosen so that X_t has the
+// requested ones. Each value uses exactly one call to RandU01; warm-up and
+// restarts behave as for ARMA.
+type ARTA struct {
+	base     *ARMA
+	marginal Invertible
+	r        []float64
+}
+
+// NewARTA returns the ARTA process with the given marginal distribution
+// and autocorrelations rho[k-1] at lags k = 1, ..., p, driven by g. The
+// marginal must have a finite positive variance. An error is returned if
+// some rho[k-1] cannot be attained with the marginal, or if the base
+// autocorrelations matching rho are not those of a stationary AR(p)
+// process.
+func NewARTA(g *RngStream, marginal Invertible, rho []float64) (*ARTA, error) {
+	if len(rho) == 0 {
+		return nil, ErrDimension
+	}
+	n := newNormalCopulaMoments(marginal.Quantile)
+	if !(n.v > 0) || math.IsInf(n.v, 1) {
+		return nil, paramError("ARTA: marginal needs a finite variance > 0")
+	}
+	r := make([]float64, len(rho))
+	for k, target := range rho {
+		rk, ok := n.baseCorrelation(target)
+		if !ok {
+			return nil, paramError("ARTA: autocorrelation %v at lag %d is not attainable", target, k+1)
+		}
+		r[k] = rk
+	}
+	phi, v, ok := levinsonDurbin(r)
+	if !ok {
+		return nil, paramError("ARTA: base autocorrelations are not those of a stationary AR process")
+	}
+	base, err := NewAR(g, 0, phi, math.Sqrt(v))
+	if err != nil {
+		return nil, err
+	}
+	return &ARTA{base: base, marginal: marginal, r: r}, nil
+}
+
+// BaseCorrelations returns the autocorrelations at lags 1, ..., p of the
+// normal base process.
+func (a *ARTA) BaseCorrelations() []float64 {
+	return append([]float64(nil), a.r...)
+}
+
+// SetWarmup sets the number of warm-up steps of the base process.
+func (a *ARTA) SetWarmup(n int) {
+	a.base.SetWarmup(n)
+}
+
+// Next returns the next value of the process.
+func (a *ARTA) Next() float64 {
+	return a.marginal.Quantile(StdNormalCDF(a.base.Next()))
+}
+
+// ResetStartSubstream restarts the process and resets its stream to the
+// beginning of the current substream.
+func (a *ARTA) ResetStartSubstream() {
+	a.base.ResetStartSubstream()
+}
+
+// ResetNextSubstream restarts the process and moves its stream to the
+// beginning of the next substream.
+func (a *ARTA) ResetNextSubstream() {
+	a.base.ResetNextSubstream()
+}
+
+// levinsonDurbin returns the coefficients of the AR(p) process with unit
+// variance and autocorrelations r[k-1] at lags k = 1, ..., p, together
+// with its innovation variance. ok is false if the autocorrelations are
+// not those of a stationary process.
+func levinsonDurbin(r []float64) (phi []float64, v float64, ok bool) {
+	v = 1
+	for k := 1; k <= len(r); k++ {
+		acc := r[k-1]
+		for j := 1; j < k; j++ {
+			acc -= phi[j-1] * r[k-1-j]
+		}
+		kappa := acc / v
+		if !(math.Abs(kappa) < 1) {
+			return nil, 0, false
+		}
+		next := make([]float64, k)
+		for j := 1; j < k; j++ {
+			next[j-1] = phi[j-1] - kappa*phi[k-1-j]
+		}
+		next[k-1] = kappa
+		phi = next
+		v *= 1 - kappa*kappa
+	}
+	return phi, v, true
+}
+
+// normalCopulaMoments computes E[q(Phi(Z1)) q(Phi(Z2))] for standard
+// normals Z1, Z2 with correlation r, by tanh-sinh quadrature over the unit
+// square on a grid fixed at construction.
+type normalCopulaMoments struct {
+	q       func(float64) float64
+	w, z, x []float64 // weights, normal nodes, q at the nodes
+	m, v    float64   // mean and variance on the same grid
+}
+
+func newNormalCopulaMoments(q func(float64) float64) *normalCopulaMoments {
+	const h = 1.0 / 8
+	n := &normalCopulaMoments{q: q}
+	for k := -24; k <= 24; k++ {
+		t := float64(k) * h
+		s := math.Pi / 2 * math.Sinh(t)
+		ch := math.Cosh(s)
+		w := h * math.Pi / 4 * math.Cosh(t) / (ch * ch)
+		u := 1 / (1 + math.Exp(-2*s))
+		if u <= 0 || u >= 1 {
+			continue
+		}
+		x := q(u)
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			continue
+		}
+		n.w = append(n.w, w)
+		n.z = append(n.z, StdNormalInv(u))
+		n.x = append(n.x, x)
+	}
+	var s1, s2 float64
+	for i, w := range n.w {
+		s1 += w * n.x[i]
+		s2 += w * n.x[i] * n.x[i]
+	}
+	n.m, n.v = s1, s2-s1*s1
+	return n
+}
+
+// correlation returns the correlation of q(Phi(Z1)) and q(Phi(Z2)).
+func (n *normalCopulaMoments) correlation(r float64) float64 {
+	s := math.Sqrt(math.Max(1-r*r, 0))
+	sum := 0.0
+	for i, wi := range n.w {
+		inner := 0.0
+		for j, wj := range n.w {
+			y := n.q(StdNormalCDF(r*n.z[i] + s*n.z[j]))
+			if math.IsInf(y, 0) || math.IsNaN(y) {
+				continue
+			}
+			inner += wj * y
+		}
+		sum += wi * n.x[i] * inner
+	}
+	return (sum - n.m*n.m) / n.v
+}
+
+// baseCorrelation returns the correlation r of the normal pair for which
+// q(Phi(Z1)) and q(Phi(Z2)) have correlation rho, by bisection, since the
+// output correlation is non-decreasing in r.
+func (n *normalCopulaMoments) baseCorrelation(rho float64) (float64, bool) {
+	if rho == 0 {
+		return 0, true
+	}
+	lo, hi := -1.0, 1.0
+	if rho > 0 {
+		lo = 0
+		if rho > n.correlation(1)+1e-9 {
+			return 0, false
+		}
+	} else {
+		hi = 0
+		if rho < n.correlation(-1)-1e-9 {
+			return 0, false
+		}
+	}
+	for hi-lo > 1e-10 {
+		mid := (lo + hi) / 2
+		if n.correlation(mid) < rho {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
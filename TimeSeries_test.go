@@ -0,0 +1,145 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// sampleACF returns the sample mean, variance and lag-1..maxLag
+// autocorrelations of x.
+func sampleACF(x []float64, maxLag int) (mean, variance float64, acf []float64) {
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	for _, v := range x {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(x))
+	acf = make([]float64, maxLag)
+	for k := 1; k <= maxLag; k++ {
+		s := 0.0
+		for i := k; i < len(x); i++ {
+			s += (x[i] - mean) * (x[i-k] - mean)
+		}
+		acf[k-1] = s / float64(len(x)) / variance
+	}
+	return mean, variance, acf
+}
+
+func TestARMA(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("arma")
+
+	ar, err := NewAR(g, 1, []float64{0.8}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(ar.Mean()-5) > 1e-12 || math.Abs(ar.Variance()-1/0.36) > 1e-9 {
+		t.Errorf("AR(1) moments (%v, %v)", ar.Mean(), ar.Variance())
+	}
+	if math.Abs(ar.Autocovariance(3)-math.Pow(0.8, 3)/0.36) > 1e-9 {
+		t.Errorf("AR(1) autocovariance at lag 3 = %v", ar.Autocovariance(3))
+	}
+
+	// ARMA(2,1) autocorrelations from the recursion
+	// gamma(k) = phi1 gamma(k-1) + phi2 gamma(k-2) for k > q
+	arma, err := NewARMA(g, 0, []float64{0.5, -0.3}, []float64{0.4}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := 2; k < 6; k++ {
+		want := 0.5*arma.Autocovariance(k-1) - 0.3*arma.Autocovariance(k-2)
+		if math.Abs(arma.Autocovariance(k)-want) > 1e-9 {
+			t.Errorf("lag %d: autocovariance %v, wanted %v", k, arma.Autocovariance(k), want)
+		}
+	}
+	x := make([]float64, 200000)
+	for i := range x {
+		x[i] = arma.Next()
+	}
+	m, v, acf := sampleACF(x, 3)
+	if math.Abs(m) > 0.05 || math.Abs(v/arma.Variance()-1) > 0.03 {
+		t.Errorf("sample moments (%v, %v), wanted (0, %v)", m, v, arma.Variance())
+	}
+	for k := 1; k <= 3; k++ {
+		if want := arma.Autocovariance(k) / arma.Variance(); math.Abs(acf[k-1]-want) > 0.01 {
+			t.Errorf("lag %d: sample autocorrelation %v, wanted %v", k, acf[k-1], want)
+		}
+	}
+
+	// the first value after warm-up is stationary: across replications
+	// on successive substreams it has the stationary mean and variance
+	ar.SetWarmup(-1)
+	if ar.Warmup() != 0 {
+		t.Errorf("negative warm-up not clamped")
+	}
+	check := func() (float64, float64) {
+		g.ResetStartStream()
+		ar.ResetStartSubstream()
+		var s1, s2 float64
+		const n = 5000
+		for r := 0; r < n; r++ {
+			y := ar.Next()
+			s1 += y
+			s2 += y * y
+			ar.ResetNextSubstream()
+		}
+		return s1 / n, s2/n - s1*s1/n/n
+	}
+	if _, v := check(); math.Abs(v*0.36-1) < 0.5 {
+		t.Errorf("expected non-stationary start without warm-up, variance %v", v)
+	}
+	ar.SetWarmup(200)
+	if m, v := check(); math.Abs(m-5) > 0.1 || math.Abs(v*0.36-1) > 0.08 {
+		t.Errorf("first value after warm-up: moments (%v, %v)", m, v)
+	}
+
+	// replications are reproducible
+	ar.ResetStartSubstream()
+	a1, a2 := ar.Next(), ar.Next()
+	ar.ResetStartSubstream()
+	if b1, b2 := ar.Next(), ar.Next(); a1 != b1 || a2 != b2 {
+		t.Errorf("substream restart gives (%v, %v), then (%v, %v)", a1, a2, b1, b2)
+	}
+
+	if _, err := NewAR(g, 0, []float64{0.5, 0.6}, 1); err == nil {
+		t.Errorf("expected error for a non-stationary AR(2)")
+	}
+	if _, err := NewAR(g, 0, []float64{1}, 1); err == nil {
+		t.Errorf("expected error for a unit root")
+	}
+}
+
+func TestARTA(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("arta")
+	expo, _ := NewExponential(1)
+	rho := []float64{0.5, 0.2}
+	a, err := NewARTA(g, expo, rho)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := a.BaseCorrelations()
+	if !(r[0] > rho[0] && r[0] < 1) {
+		t.Errorf("base lag-1 correlation %v, expected above %v", r[0], rho[0])
+	}
+	x := make([]float64, 200000)
+	for i := range x {
+		x[i] = a.Next()
+	}
+	m, v, acf := sampleACF(x, 2)
+	if math.Abs(m-1) > 0.03 || math.Abs(v-1) > 0.06 {
+		t.Errorf("sample moments (%v, %v), wanted (1, 1)", m, v)
+	}
+	for k, want := range rho {
+		if math.Abs(acf[k]-want) > 0.02 {
+			t.Errorf("lag %d: sample autocorrelation %v, wanted %v", k+1, acf[k], want)
+		}
+	}
+
+	// the exponential marginal cannot reach correlation -1
+	if _, err := NewARTA(g, expo, []float64{-0.9}); err == nil {
+		t.Errorf("expected error for an unattainable correlation")
+	}
+}
@@ -0,0 +1,443 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// TrafficSource generates the amount of traffic, in bytes or packets, in
+// successive time slots. ResetStartSubstream and ResetNextSubstream
+// restart the source together with its stream, so that each replication
+// regenerates an identical trace from its substream.
+type TrafficSource interface {
+	Next() float64
+	ResetStartSubstream()
+	ResetNextSubstream()
+}
+
+// TrafficFactory creates traffic sources, each driven by its own new
+// stream, so that the traces of different sources never overlap and are
+// fully reproducible for a given package seed. The factory also moves all
+// the sources it created to the next substream at once, for the next
+// replication.
+//
+// Every call to a source constructor of the factory creates a new stream,
+// named after the factory and the number of the call, even if the call
+// fails, so that the streams and names of later sources do not depend on
+// whether earlier calls succeeded.
+type TrafficFactory struct {
+	name    string
+	calls   int // streams created
+	sources []TrafficSource
+}
+
+// NewTrafficFactory returns a factory whose streams are named after name.
+func NewTrafficFactory(name string) *TrafficFactory {
+	return &TrafficFactory{name: name}
+}
+
+func (f *TrafficFactory) stream() *RngStream {
+	f.calls++
+	return New(fmt.Sprintf("%s-%d", f.name, f.calls-1))
+}
+
+// OnOff returns a new source of superposed ON/OFF traffic; see
+// NewOnOffTraffic.
+func (f *TrafficFactory) OnOff(n int, rate, slot, onShape, onMin, offShape, offMin float64) (*OnOffTraffic, error) {
+	s, err := NewOnOffTraffic(f.stream(), n, rate, slot, onShape, onMin, offShape, offMin)
+	if err == nil {
+		f.sources = append(f.sources, s)
+	}
+	return s, err
+}
+
+// FGN returns a new source of fractional Gaussian noise traffic; see
+// NewFGNTraffic.
+func (f *TrafficFactory) FGN(mean, sigma, hurst float64, block int) (*FGNTraffic, error) {
+	s, err := NewFGNTraffic(f.stream(), mean, sigma, hurst, block)
+	if err == nil {
+		f.sources = append(f.sources, s)
+	}
+	return s, err
+}
+
+// BModel returns a new source of b-model traffic; see NewBModelTraffic.
+func (f *TrafficFactory) BModel(volume, b float64, levels int) (*BModelTraffic, error) {
+	s, err := NewBModelTraffic(f.stream(), volume, b, levels)
+	if err == nil {
+		f.sources = append(f.sources, s)
+	}
+	return s, err
+}
+
+// Sources returns the sources created so far, in order of creation.
+func (f *TrafficFactory) Sources() []TrafficSource {
+	return append([]TrafficSource(nil), f.sources...)
+}
+
+// ResetStartSubstream restarts all the sources at the beginning of their
+// current substream.
+func (f *TrafficFactory) ResetStartSubstream() {
+	for _, s := range f.sources {
+		s.ResetStartSubstream()
+	}
+}
+
+// ResetNextSubstream moves all the sources to the beginning of their next
+// substream, for the next replication.
+func (f *TrafficFactory) ResetNextSubstream() {
+	for _, s := range f.sources {
+		s.ResetNextSubstream()
+	}
+}
+
+// OnOffTraffic is the superposition of n independent ON/OFF sources that
+// emit at a constant rate while ON and are silent while OFF, with Pareto
+// distributed ON and OFF periods. With shapes between 1 and 2 the periods
+// have infinite variance and the aggregate traffic is long-range
+// dependent, with Hurst parameter (3 - min(onShape, offShape))/2 (Taqqu,
+// Willinger and Sherman, 1997).
+//
+// Each source starts in its stationary regime: ON with probability
+// E[on]/(E[on]+E[off]), with a residual period drawn from the equilibrium
+// distribution, at a cost of two calls to RandU01. Every later period
+// uses one call to RandU01, by inversion.
+type OnOffTraffic struct {
+	g                *RngStream
+	rate, slot       float64
+	onShape, onMin   float64
+	offShape, offMin float64
+	on               []bool
+	left             []float64 // time left in the current period
+	started          bool
+}
+
+// NewOnOffTraffic returns the superposition of n >= 1 ON/OFF sources
+// emitting rate units per unit of time while ON, observed in slots of
+// length slot. ON periods are Pareto with shape onShape > 1 and minimum
+// onMin > 0, OFF periods Pareto with shape offShape > 1 and minimum
+// offMin > 0.
+func NewOnOffTraffic(g *RngStream, n int, rate, slot, onShape, onMin, offShape, offMin float64) (*OnOffTraffic, error) {
+	if n < 1 {
+		return nil, paramError("on/off traffic: need n >= 1")
+	}
+	for _, v := range []float64{rate, slot, onMin, offMin} {
+		if !(v > 0) || math.IsInf(v, 1) {
+			return nil, paramError("on/off traffic: need finite rate, slot and minima > 0")
+		}
+	}
+	if !(onShape > 1) || !(offShape > 1) || math.IsInf(onShape, 1) || math.IsInf(offShape, 1) {
+		return nil, paramError("on/off traffic: need finite shapes > 1")
+	}
+	return &OnOffTraffic{g: g, rate: rate, slot: slot,
+		onShape: onShape, onMin: onMin, offShape: offShape, offMin: offMin,
+		on: make([]bool, n), left: make([]float64, n)}, nil
+}
+
+// Hurst returns the Hurst parameter of the aggregate traffic, or 1/2 if
+// both shapes are at least 2.
+func (s *OnOffTraffic) Hurst() float64 {
+	a := math.Min(s.onShape, s.offShape)
+	if a >= 2 {
+		return 0.5
+	}
+	return (3 - a) / 2
+}
+
+// Mean returns the mean amount of traffic per slot.
+func (s *OnOffTraffic) Mean() float64 {
+	on := paretoMean(s.onShape, s.onMin)
+	off := paretoMean(s.offShape, s.offMin)
+	return float64(len(s.on)) * s.rate * s.slot * on / (on + off)
+}
+
+// Next returns the amount of traffic in the next slot.
+func (s *OnOffTraffic) Next() float64 {
+	if !s.started {
+		s.started = true
+		on := paretoMean(s.onShape, s.onMin)
+		off := paretoMean(s.offShape, s.offMin)
+		for i := range s.on {
+			s.on[i] = s.g.RandU01() < on/(on+off)
+			if s.on[i] {
+				s.left[i] = paretoResidual(s.g.RandU01(), s.onShape, s.onMin)
+			} else {
+				s.left[i] = paretoResidual(s.g.RandU01(), s.offShape, s.offMin)
+			}
+		}
+	}
+	total := 0.0
+	for i := range s.on {
+		t := s.slot // time left in the slot
+		for s.left[i] <= t {
+			if s.on[i] {
+				total += s.left[i]
+			}
+			t -= s.left[i]
+			s.on[i] = !s.on[i]
+			if s.on[i] {
+				s.left[i] = s.onMin * math.Pow(s.g.RandU01(), -1/s.onShape)
+			} else {
+				s.left[i] = s.offMin * math.Pow(s.g.RandU01(), -1/s.offShape)
+			}
+		}
+		if s.on[i] {
+			total += t
+		}
+		s.left[i] -= t
+	}
+	return s.rate * total
+}
+
+// ResetStartSubstream restarts the sources in their stationary regime
+// and resets the stream to the beginning of its current substream.
+func (s *OnOffTraffic) ResetStartSubstream() {
+	s.g.ResetStartSubstream()
+	s.started = false
+}
+
+// ResetNextSubstream restarts the sources in their stationary regime and
+// moves the stream to the beginning of its next substream.
+func (s *OnOffTraffic) ResetNextSubstream() {
+	s.g.ResetNextSubstream()
+	s.started = false
+}
+
+// paretoMean returns the mean of the Pareto distribution with shape a > 1
+// and minimum m.
+func paretoMean(a, m float64) float64 {
+	return a * m / (a - 1)
+}
+
+// paretoResidual returns, by inversion of u, a variate of the equilibrium
+// (residual life) distribution of the Pareto distribution with shape
+// a > 1 and minimum m, whose density is P(X > x)/E[X].
+func paretoResidual(u, a, m float64) float64 {
+	if u < (a-1)/a {
+		return u * paretoMean(a, m)
+	}
+	return m * math.Pow(a*(1-u), -1/(a-1))
+}
+
+// FGNTraffic generates traffic as mean + sigma Z_t, for Z_t standard
+// fractional Gaussian noise with Hurst parameter H, whose autocovariance
+// is (|k+1|^2H - 2|k|^2H + |k-1|^2H)/2. Values are generated exactly, in
+// blocks of a fixed length, with the circulant embedding method of Davies
+// and Harte (1987); successive blocks are independent, so the block
+// length should cover the horizon of interest. Each block of length n
+// uses exactly 2M calls to RandU01, for M the smallest power of two with
+// M >= n - 1, all normals being generated by inversion. The values are
+// not truncated at zero.
+type FGNTraffic struct {
+	g           *RngStream
+	mean, sigma float64
+	hurst       float64
+	sqrtEig     []float64 // sqrt(lambda_k / 2M)
+	w           []complex128
+	buf         []float64
+	pos         int
+}
+
+// NewFGNTraffic returns the fractional Gaussian noise source with the
+// given mean and standard deviation sigma > 0 per slot, Hurst parameter
+// 0 < hurst < 1, and block length block >= 2.
+func NewFGNTraffic(g *RngStream, mean, sigma, hurst float64, block int) (*FGNTraffic, error) {
+	if !(hurst > 0 && hurst < 1) {
+		return nil, paramError("FGN traffic: need 0 < hurst < 1")
+	}
+	if !(sigma > 0) || math.IsInf(sigma, 1) || math.IsNaN(mean) || math.IsInf(mean, 0) {
+		return nil, paramError("FGN traffic: need finite mean and sigma > 0")
+	}
+	if block < 2 {
+		return nil, paramError("FGN traffic: need block >= 2")
+	}
+	m := 1 << bits.Len(uint(block-2)) // smallest power of two >= block-1
+	c := make([]complex128, 2*m)
+	for k := 0; k <= m; k++ {
+		c[k] = complex(fgnCovariance(hurst, k), 0)
+		if k > 0 && k < m {
+			c[2*m-k] = c[k]
+		}
+	}
+	fft(c)
+	s := &FGNTraffic{g: g, mean: mean, sigma: sigma, hurst: hurst,
+		sqrtEig: make([]float64, 2*m),
+		w:       make([]complex128, 2*m),
+		buf:     make([]float64, block),
+		pos:     block,
+	}
+	for k, l := range c {
+		// the eigenvalues are non-negative for fGn; clip rounding errors
+		s.sqrtEig[k] = math.Sqrt(math.Max(real(l), 0) / float64(2*m))
+	}
+	return s, nil
+}
+
+// fgnCovariance returns the autocovariance at lag k of standard
+// fractional Gaussian noise with Hurst parameter h.
+func fgnCovariance(h float64, k int) float64 {
+	x := float64(k)
+	return (math.Pow(math.Abs(x+1), 2*h) - 2*math.Pow(x, 2*h) + math.Pow(math.Abs(x-1), 2*h)) / 2
+}
+
+// Hurst returns the Hurst parameter.
+func (s *FGNTraffic) Hurst() float64 {
+	return s.hurst
+}
+
+// Mean returns the mean amount of traffic per slot.
+func (s *FGNTraffic) Mean() float64 {
+	return s.mean
+}
+
+// Next returns the amount of traffic in the next slot.
+func (s *FGNTraffic) Next() float64 {
+	if s.pos == len(s.buf) {
+		s.block()
+	}
+	s.pos++
+	return s.mean + s.sigma*s.buf[s.pos-1]
+}
+
+func (s *FGNTraffic) block() {
+	m := len(s.w) / 2
+	w := s.w
+	w[0] = complex(s.sqrtEig[0]*StdNormalInv(s.g.RandU01()), 0)
+	w[m] = complex(s.sqrtEig[m]*StdNormalInv(s.g.RandU01()), 0)
+	for k := 1; k < m; k++ {
+		re := StdNormalInv(s.g.RandU01())
+		im := StdNormalInv(s.g.RandU01())
+		w[k] = complex(re, im) * complex(s.sqrtEig[k]/math.Sqrt2, 0)
+		w[2*m-k] = cmplx.Conj(w[k])
+	}
+	fft(w)
+	for i := range s.buf {
+		s.buf[i] = real(w[i])
+	}
+	s.pos = 0
+}
+
+// ResetStartSubstream discards the current block and resets the stream to
+// the beginning of its current substream.
+func (s *FGNTraffic) ResetStartSubstream() {
+	s.g.ResetStartSubstream()
+	s.pos = len(s.buf)
+}
+
+// ResetNextSubstream discards the current block and moves the stream to
+// the beginning of its next substream.
+func (s *FGNTraffic) ResetNextSubstream() {
+	s.g.ResetNextSubstream()
+	s.pos = len(s.buf)
+}
+
+// fft replaces a, whose length must be a power of two, by its discrete
+// Fourier transform sum_j a_j exp(-2 pi i jk/n), computed in place with
+// the iterative radix-2 algorithm.
+func fft(a []complex128) {
+	n := len(a)
+	if n&(n-1) != 0 {
+		panic(ErrDimension)
+	}
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, -2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u := a[start+k]
+				v := a[start+k+size/2] * w
+				a[start+k] = u + v
+				a[start+k+size/2] = u - v
+				w *= step
+			}
+		}
+	}
+}
+
+// BModelTraffic generates traffic with the b-model of Wang, Ailamaki and
+// Faloutsos (2002): a volume of traffic over 2^levels slots is split in
+// two halves carrying fractions b and 1-b, the heavier half chosen at
+// random, and each half is split again recursively down to single slots.
+// The result is self-similar (multifractal) and bursty at all time scales
+// for b away from 1/2. Each block of 2^levels slots uses exactly
+// 2^levels - 1 calls to RandU01, one per split; successive blocks are
+// independent.
+type BModelTraffic struct {
+	g      *RngStream
+	volume float64
+	b      float64
+	buf    []float64
+	pos    int
+}
+
+// NewBModelTraffic returns the b-model source distributing volume > 0
+// over blocks of 2^levels slots, 1 <= levels <= 30, with bias
+// 1/2 <= b < 1.
+func NewBModelTraffic(g *RngStream, volume, b float64, levels int) (*BModelTraffic, error) {
+	if !(volume > 0) || math.IsInf(volume, 1) {
+		return nil, paramError("b-model traffic: need finite volume > 0")
+	}
+	if !(b >= 0.5 && b < 1) {
+		return nil, paramError("b-model traffic: need 1/2 <= b < 1")
+	}
+	if levels < 1 || levels > 30 {
+		return nil, paramError("b-model traffic: need 1 <= levels <= 30")
+	}
+	n := 1 << levels
+	return &BModelTraffic{g: g, volume: volume, b: b, buf: make([]float64, n), pos: n}, nil
+}
+
+// Mean returns the mean amount of traffic per slot.
+func (s *BModelTraffic) Mean() float64 {
+	return s.volume / float64(len(s.buf))
+}
+
+// Next returns the amount of traffic in the next slot.
+func (s *BModelTraffic) Next() float64 {
+	if s.pos == len(s.buf) {
+		s.buf[0] = s.volume
+		// split breadth first, level by level, in place
+		for width := len(s.buf); width > 1; width /= 2 {
+			for start := 0; start < len(s.buf); start += width {
+				v := s.buf[start]
+				f := s.b
+				if s.g.RandU01() < 0.5 {
+					f = 1 - f
+				}
+				s.buf[start] = f * v
+				s.buf[start+width/2] = (1 - f) * v
+			}
+		}
+		s.pos = 0
+	}
+	s.pos++
+	return s.buf[s.pos-1]
+}
+
+// ResetStartSubstream discards the current block and resets the stream to
+// the beginning of its current substream.
+func (s *BModelTraffic) ResetStartSubstream() {
+	s.g.ResetStartSubstream()
+	s.pos = len(s.buf)
+}
+
+// ResetNextSubstream discards the current block and moves the stream to
+// the beginning of its next substream.
+func (s *BModelTraffic) ResetNextSubstream() {
+	s.g.ResetNextSubstream()
+	s.pos = len(s.buf)
+}
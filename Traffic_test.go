@@ -0,0 +1,166 @@
+package rngstream
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+var (
+	_ TrafficSource = (*OnOffTraffic)(nil)
+	_ TrafficSource = (*FGNTraffic)(nil)
+	_ TrafficSource = (*BModelTraffic)(nil)
+)
+
+func TestFFT(t *testing.T) {
+	a := []complex128{1, 2i, -3, 4, 0.5, -1i, 2, 7}
+	b := append([]complex128(nil), a...)
+	fft(b)
+	for k := range a {
+		var want complex128
+		for j, v := range a {
+			want += v * cmplx.Rect(1, -2*math.Pi*float64(j*k)/float64(len(a)))
+		}
+		if cmplx.Abs(b[k]-want) > 1e-12 {
+			t.Errorf("coefficient %d = %v, wanted %v", k, b[k], want)
+		}
+	}
+}
+
+func TestFGNTraffic(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	f := NewTrafficFactory("fgn")
+	const h = 0.8
+	s, err := f.FGN(10, 2, h, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sample autocorrelations within blocks, over many blocks
+	const blocks = 200
+	var sum, sq, lag1, lag10 float64
+	n := 0
+	x := make([]float64, 1000)
+	for b := 0; b < blocks; b++ {
+		for i := range x {
+			x[i] = (s.Next() - 10) / 2
+			sum += x[i]
+			sq += x[i] * x[i]
+		}
+		for i := 10; i < len(x); i++ {
+			lag10 += x[i] * x[i-10]
+		}
+		for i := 1; i < len(x); i++ {
+			lag1 += x[i] * x[i-1]
+		}
+		n += len(x)
+	}
+	if m := sum / float64(n); math.Abs(m) > 0.1 {
+		t.Errorf("sample mean %v", m)
+	}
+	if v := sq / float64(n); math.Abs(v-1) > 0.05 {
+		t.Errorf("sample variance %v", v)
+	}
+	if r, want := lag1/float64(n-blocks), fgnCovariance(h, 1); math.Abs(r-want) > 0.03 {
+		t.Errorf("lag 1 covariance %v, wanted %v", r, want)
+	}
+	if r, want := lag10/float64(n-10*blocks), fgnCovariance(h, 10); math.Abs(r-want) > 0.03 {
+		t.Errorf("lag 10 covariance %v, wanted %v", r, want)
+	}
+
+	if _, err := f.FGN(0, 1, 1, 100); err == nil {
+		t.Errorf("expected error for hurst = 1")
+	}
+	if len(f.Sources()) != 1 {
+		t.Errorf("factory has %d sources, wanted 1", len(f.Sources()))
+	}
+	// the failed call still used up its stream name
+	s2, err := f.FGN(0, 1, 0.7, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.g.name != "fgn-0" || s2.g.name != "fgn-2" {
+		t.Errorf("stream names %q and %q, wanted fgn-0 and fgn-2", s.g.name, s2.g.name)
+	}
+}
+
+func TestOnOffTraffic(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	f := NewTrafficFactory("onoff")
+	s, err := f.OnOff(50, 2, 1, 1.5, 1, 1.5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Hurst() != 0.75 {
+		t.Errorf("Hurst = %v, wanted 0.75", s.Hurst())
+	}
+	// the mean is 50 * 2 * 3/(3+6)
+	if math.Abs(s.Mean()-100.0/3) > 1e-12 {
+		t.Errorf("Mean = %v", s.Mean())
+	}
+	// slots carry at most n*rate*slot; the initial regime is stationary, so
+	// the first slot has the stationary mean across replications
+	var first float64
+	const reps = 2000
+	for r := 0; r < reps; r++ {
+		v := s.Next()
+		if v < 0 || v > 100 {
+			t.Fatalf("slot volume %v out of range", v)
+		}
+		first += v
+		f.ResetNextSubstream()
+	}
+	if m := first / reps; math.Abs(m-s.Mean()) > 1 {
+		t.Errorf("mean of the first slot %v, wanted %v", m, s.Mean())
+	}
+	var total float64
+	const n = 50000
+	for i := 0; i < n; i++ {
+		total += s.Next()
+	}
+	// heavy tails make the sample mean converge slowly
+	if m := total / n; math.Abs(m-s.Mean()) > 3 {
+		t.Errorf("sample mean %v, wanted %v", m, s.Mean())
+	}
+}
+
+func TestBModelTraffic(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	f := NewTrafficFactory("bmodel")
+	s, err := f.BModel(1024, 0.7, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := f.BModel(1024, 0.7, 10)
+	sum, max := 0.0, 0.0
+	same := true
+	for i := 0; i < 1024; i++ {
+		v := s.Next()
+		sum += v
+		max = math.Max(max, v)
+		if other.Next() != v {
+			same = false
+		}
+	}
+	if math.Abs(sum-1024) > 1e-9 {
+		t.Errorf("block carries %v, wanted 1024", sum)
+	}
+	if want := 1024 * math.Pow(0.7, 10); math.Abs(max-want) > 1e-9 {
+		t.Errorf("largest slot %v, wanted %v", max, want)
+	}
+	if same {
+		t.Errorf("sources from the same factory produced identical traces")
+	}
+
+	// restarting the substream reproduces the trace
+	f.ResetStartSubstream()
+	a := []float64{s.Next(), s.Next(), s.Next()}
+	f.ResetStartSubstream()
+	for i, want := range a {
+		if got := s.Next(); got != want {
+			t.Errorf("slot %d: %v after restart, wanted %v", i, got, want)
+		}
+	}
+	if _, err := f.BModel(1, 0.4, 3); err == nil {
+		t.Errorf("expected error for b < 1/2")
+	}
+}
@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import "math"
+
+// Invertible is implemented by distributions that provide both their
+// distribution function and its inverse, and can therefore be sampled
+// by inversion.
+type Invertible interface {
+	CDF(x float64) float64
+	Quantile(u float64) float64
+}
+
+// CustomInvertible adapts a user-provided distribution function and its
+// inverse to the Invertible interface.
+type CustomInvertible struct {
+	CDFFunc      func(x float64) float64
+	QuantileFunc func(u float64) float64
+}
+
+// CDF returns CDFFunc(x).
+func (d *CustomInvertible) CDF(x float64) float64 {
+	return d.CDFFunc(x)
+}
+
+// Quantile returns QuantileFunc(u).
+func (d *CustomInvertible) Quantile(u float64) float64 {
+	return d.QuantileFunc(u)
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *CustomInvertible) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// Mean returns the mean, computed by numerical integration of the
+// quantile function.
+func (d *CustomInvertible) Mean() float64 {
+	m, _ := quantileMoments(d.QuantileFunc)
+	return m
+}
+
+// Variance returns the variance, computed by numerical integration of the
+// quantile function.
+func (d *CustomInvertible) Variance() float64 {
+	_, v := quantileMoments(d.QuantileFunc)
+	return v
+}
+
+// Survival is implemented by distributions that provide their survival
+// function 1 - F(x) and its inverse, computed without the cancellation
+// of 1 - CDF(x) in the upper tail.
+type Survival interface {
+	Survival(x float64) float64
+	InverseSurvival(p float64) float64
+}
+
+// Truncated is an invertible distribution restricted to the interval
+// [a, b]. It is sampled by mapping the uniform into [F(a-), F(b)] before
+// inverting, never by rejection, so each variate consumes exactly one
+// uniform and streams used for common random numbers stay synchronized.
+//
+// When a lies in the upper half of the base distribution and the base
+// implements Survival, the truncated distribution is computed from the
+// survival function instead, so that far upper tails keep their
+// precision; otherwise they are limited by the rounding of 1 - F(a).
+type Truncated struct {
+	base   Invertible
+	a, b   float64
+	fa, fb float64  // F(a-) and F(b), or 1 - F(a-) and 1 - F(b) with surv
+	surv   Survival // the base, in the upper tail
+}
+
+// NewTruncated returns the distribution d truncated to [a, b]. Either
+// bound may be infinite. If d is Discrete, the bounds are included: the
+// distribution starts at P(X < a), which is F(ceil(a) - 1). It fails if
+// a >= b or if d puts no probability on [a, b].
+func NewTruncated(d Invertible, a, b float64) (*Truncated, error) {
+	if !(a < b) {
+		return nil, paramError("truncated: need a < b")
+	}
+	// F(a-) = P(X < a), with its argument below a for discrete laws
+	left := a
+	if _, ok := d.(Discrete); ok {
+		left = math.Ceil(a) - 1
+	}
+	t := &Truncated{base: d, a: a, b: b, fb: 1}
+	if s, ok := d.(Survival); ok && !math.IsInf(a, -1) && d.CDF(left) > 0.5 {
+		t.surv = s
+		t.fa, t.fb = s.Survival(left), 0
+		if !math.IsInf(b, 1) {
+			t.fb = s.Survival(b)
+		}
+		if !(t.fa > t.fb) {
+			return nil, paramError("truncated: [%v, %v] has probability zero", a, b)
+		}
+		return t, nil
+	}
+	if !math.IsInf(a, -1) {
+		t.fa = d.CDF(left)
+	}
+	if !math.IsInf(b, 1) {
+		t.fb = d.CDF(b)
+	}
+	if !(t.fb > t.fa) {
+		return nil, paramError("truncated: [%v, %v] has probability zero", a, b)
+	}
+	return t, nil
+}
+
+// CDF returns the distribution function at x.
+func (d *Truncated) CDF(x float64) float64 {
+	if x < d.a {
+		return 0
+	}
+	if x >= d.b {
+		return 1
+	}
+	if d.surv != nil {
+		return (d.fa - d.surv.Survival(x)) / (d.fa - d.fb)
+	}
+	return (d.base.CDF(x) - d.fa) / (d.fb - d.fa)
+}
+
+// Quantile returns the inverse of the distribution function at u. The
+// result is clipped to [a, b] to guard against rounding in the base
+// quantile function.
+func (d *Truncated) Quantile(u float64) float64 {
+	var x float64
+	if d.surv != nil {
+		x = d.surv.InverseSurvival(d.fa - u*(d.fa-d.fb))
+	} else {
+		x = d.base.Quantile(d.fa + u*(d.fb-d.fa))
+	}
+	if x < d.a {
+		return d.a
+	}
+	if x > d.b {
+		return d.b
+	}
+	return x
+}
+
+// Sample returns a variate generated by inversion from one call to RandU01.
+func (d *Truncated) Sample(g *RngStream) float64 {
+	return d.Quantile(g.RandU01())
+}
+
+// Mean returns the mean, computed by numerical integration of the
+// quantile function.
+func (d *Truncated) Mean() float64 {
+	m, _ := quantileMoments(d.Quantile)
+	return m
+}
+
+// Variance returns the variance, computed by numerical integration of the
+// quantile function.
+func (d *Truncated) Variance() float64 {
+	_, v := quantileMoments(d.Quantile)
+	return v
+}
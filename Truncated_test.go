@@ -0,0 +1,106 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTruncated(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("trunc")
+
+	norm, _ := NewNormal(0, 1)
+	expo, _ := NewExponential(2)
+	weib, _ := NewWeibull(1.5, 3)
+	logn, _ := NewLogNormal(0, 0.5)
+	custom := &CustomInvertible{
+		CDFFunc:      func(x float64) float64 { return x * x },
+		QuantileFunc: math.Sqrt,
+	}
+
+	cases := []struct {
+		name string
+		d    Invertible
+		a, b float64
+	}{
+		{"normal", norm, -0.5, 2},
+		{"normal tail", norm, 3, math.Inf(1)},
+		{"exponential", expo, 0.1, 0.4},
+		{"weibull", weib, 1, 4},
+		{"lognormal", logn, 0.8, 1.5},
+		{"custom", custom, 0.2, 0.7},
+	}
+	for _, c := range cases {
+		tr, err := NewTruncated(c.d, c.a, c.b)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		below := 0
+		mid := tr.Quantile(0.5)
+		for i := 0; i < 10000; i++ {
+			x := tr.Sample(g)
+			if x < c.a || x > c.b {
+				t.Fatalf("%s: sample %v outside [%v, %v]", c.name, x, c.a, c.b)
+			}
+			if x <= mid {
+				below++
+			}
+		}
+		if math.Abs(float64(below)/10000-0.5) > 0.02 {
+			t.Errorf("%s: fraction below median = %v", c.name, float64(below)/10000)
+		}
+		if u := tr.CDF(mid); math.Abs(u-0.5) > 1e-9 {
+			t.Errorf("%s: CDF(Quantile(0.5)) = %v", c.name, u)
+		}
+	}
+
+	// far upper tails are computed from the survival function; the mean
+	// of the normal beyond 9 is phi(9)/(1 - Phi(9))
+	tail, err := NewTruncated(norm, 9, math.Inf(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, want := tail.Mean(), norm.PDF(9)/norm.Survival(9); math.Abs(m-want) > 1e-6 {
+		t.Errorf("normal beyond 9: mean = %v, wanted %v", m, want)
+	}
+	for _, u := range []float64{1e-6, 0.3, 0.9} {
+		if c := tail.CDF(tail.Quantile(u)); math.Abs(c-u) > 1e-9 {
+			t.Errorf("normal beyond 9: CDF(Quantile(%v)) = %v", u, c)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		if x := tail.Sample(g); !(x >= 9 && x < 20) {
+			t.Fatalf("normal beyond 9: sample %v", x)
+		}
+	}
+
+	// discrete bases keep the lower bound
+	bin, _ := NewBinomial(10, 0.5)
+	tb, err := NewTruncated(bin, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := tb.CDF(3); math.Abs(c-bin.PMF(3)/(bin.CDF(5)-bin.CDF(2))) > 1e-12 {
+		t.Errorf("binomial on [3, 5]: CDF(3) = %v", c)
+	}
+	counts := make(map[float64]int)
+	for i := 0; i < 10000; i++ {
+		counts[tb.Sample(g)]++
+	}
+	for k := 3; k <= 5; k++ {
+		p := bin.PMF(k) / (bin.CDF(5) - bin.CDF(2))
+		if f := float64(counts[float64(k)]) / 10000; math.Abs(f-p) > 4*math.Sqrt(p*(1-p)/10000) {
+			t.Errorf("binomial on [3, 5]: frequency of %d = %v, wanted %v", k, f, p)
+		}
+	}
+	if len(counts) != 3 {
+		t.Errorf("binomial on [3, 5]: samples %v", counts)
+	}
+
+	if _, err := NewTruncated(expo, -2, -1); err == nil {
+		t.Errorf("expected error for zero-probability interval")
+	}
+	if _, err := NewWeibull(-1, 1); err == nil {
+		t.Errorf("expected error for negative Weibull shape")
+	}
+}
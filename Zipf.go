@@ -0,0 +1,309 @@
+// SPDX-License-Identifier: MIT
+
+package rngstream
+
+import (
+	"math"
+	"sort"
+)
+
+// Zipf generates integers k in {kmin, ..., n} with probability
+// proportional to (k + q)^(-s). This covers the bounded and unbounded
+// Zipf laws (q = 0), the Zipf-Mandelbrot law (q > 0) and the discrete
+// power law above kmin.
+//
+// By default Zipf uses the rejection-inversion method of Hörmann and
+// Derflinger (1996), whose setup and sampling costs do not depend on n.
+// Each trial makes one call to RandU01, and the acceptance probability is
+// high, but the number of trials is random. SetTableInversion switches a
+// bounded law to exact inversion of a precomputed distribution table,
+// which makes exactly one call to RandU01 per variate, as needed for
+// common random numbers, at a memory cost proportional to n.
+//
+// Zipf implements Discrete. Its distribution function and moments are
+// computed from sums of (k + q)^(-s), added term by term for the first
+// terms and approximated by the Euler-Maclaurin formula beyond.
+type Zipf struct {
+	s, q    float64
+	kmin, n int // n = 0 means no upper bound
+
+	norm        float64   // sum of (k + q)^(-s) over the support
+	hx1, hn, sq float64   // rejection-inversion constants
+	table       []float64 // cumulative probabilities, in table mode
+}
+
+// NewZipf returns the Zipf law on {1, ..., n} with exponent s > 0, or the
+// unbounded Zipf law on {1, 2, ...} if n = 0, in which case s > 1.
+func NewZipf(s float64, n int) (*Zipf, error) {
+	return newZipf(s, 0, 1, n)
+}
+
+// NewZipfMandelbrot returns the Zipf-Mandelbrot law on {1, ..., n}, with
+// probabilities proportional to (k + q)^(-s), s > 0 and q >= 0. As for
+// NewZipf, n = 0 gives the unbounded law and requires s > 1.
+func NewZipfMandelbrot(s, q float64, n int) (*Zipf, error) {
+	return newZipf(s, q, 1, n)
+}
+
+// NewDiscretePowerLaw returns the discrete power law on {kmin, kmin+1, ...},
+// kmin >= 1, with probabilities proportional to k^(-alpha), alpha > 1.
+func NewDiscretePowerLaw(alpha float64, kmin int) (*Zipf, error) {
+	return newZipf(alpha, 0, kmin, 0)
+}
+
+func newZipf(s, q float64, kmin, n int) (*Zipf, error) {
+	if !(s > 0) || math.IsInf(s, 1) {
+		return nil, paramError("zipf: need finite s > 0")
+	}
+	if !(q >= 0) || math.IsInf(q, 1) {
+		return nil, paramError("zipf: need finite q >= 0")
+	}
+	if kmin < 1 || n < 0 || (n > 0 && n < kmin) {
+		return nil, paramError("zipf: need 1 <= kmin <= n, or n = 0")
+	}
+	if n == 0 && !(s > 1) {
+		return nil, paramError("zipf: the unbounded law needs s > 1")
+	}
+	z := &Zipf{s: s, q: q, kmin: kmin, n: n}
+	k := float64(kmin)
+	z.hx1 = z.hIntegral(k+0.5) - z.h(k)
+	if n == 0 {
+		z.hn = 1 / (s - 1) // limit of hIntegral at infinity
+	} else {
+		z.hn = z.hIntegral(float64(n) + 0.5)
+	}
+	z.sq = k + 1 - z.hIntegralInv(z.hIntegral(k+1.5)-z.h(k+1))
+	z.norm = powerSum(s, q, k, z.last())
+	return z, nil
+}
+
+// last returns the largest value of the support, +Inf for the unbounded
+// laws.
+func (z *Zipf) last() float64 {
+	if z.n == 0 {
+		return math.Inf(1)
+	}
+	return float64(z.n)
+}
+
+// SetTableInversion selects exact table inversion (t = true) or
+// rejection-inversion (t = false). It returns false, and leaves the
+// method unchanged, if table inversion is requested for an unbounded law.
+func (z *Zipf) SetTableInversion(t bool) bool {
+	if !t {
+		z.table = nil
+		return true
+	}
+	if z.n == 0 {
+		return false
+	}
+	if z.table != nil {
+		return true
+	}
+	table := make([]float64, z.n-z.kmin+1)
+	sum := 0.0
+	for i := range table {
+		sum += z.h(float64(z.kmin + i))
+		table[i] = sum
+	}
+	for i := range table {
+		table[i] /= sum
+	}
+	table[len(table)-1] = 1
+	z.table = table
+	return true
+}
+
+// PMF returns the probability of k.
+func (z *Zipf) PMF(k int) float64 {
+	if k < z.kmin || (z.n > 0 && k > z.n) {
+		return 0
+	}
+	return z.h(float64(k)) / z.norm
+}
+
+// CDF returns the probability of a variate at most x.
+func (z *Zipf) CDF(x float64) float64 {
+	k := math.Floor(x)
+	switch {
+	case k < float64(z.kmin):
+		return 0
+	case k >= z.last():
+		return 1
+	case k < float64(z.kmin)+powerSumTerms:
+		return powerSum(z.s, z.q, float64(z.kmin), k) / z.norm
+	}
+	return 1 - powerSum(z.s, z.q, k+1, z.last())/z.norm
+}
+
+// Quantile returns the smallest k such that CDF(k) > u. For the unbounded
+// laws, it is +Inf when k exceeds the largest float64.
+func (z *Zipf) Quantile(u float64) float64 {
+	if u >= 1 {
+		return z.last()
+	}
+	if z.table != nil {
+		return float64(z.kmin + sort.Search(len(z.table), func(i int) bool { return z.table[i] > u }))
+	}
+	// CDF(lo) <= u < CDF(hi): double the distance to kmin, then bisect
+	kmin := float64(z.kmin)
+	lo, hi := kmin-1, kmin
+	for z.CDF(hi) <= u {
+		lo, hi = hi, math.Min(kmin+2*(hi-kmin+1), z.last())
+		if math.IsInf(hi, 1) {
+			return hi
+		}
+	}
+	for hi-lo > 1 {
+		mid := math.Floor(lo/2 + hi/2)
+		if mid == lo || mid == hi {
+			break // beyond 2^53, where not every integer is a float64
+		}
+		if z.CDF(mid) <= u {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// Mean returns the mean, +Inf for the unbounded laws with s <= 2.
+func (z *Zipf) Mean() float64 {
+	if z.n == 0 && z.s <= 2 {
+		return math.Inf(1)
+	}
+	return powerSum(z.s-1, z.q, float64(z.kmin), z.last())/z.norm - z.q
+}
+
+// Variance returns the variance, +Inf for the unbounded laws with
+// s <= 3.
+func (z *Zipf) Variance() float64 {
+	if z.n == 0 && z.s <= 3 {
+		return math.Inf(1)
+	}
+	// first two moments of k + q
+	m1 := powerSum(z.s-1, z.q, float64(z.kmin), z.last()) / z.norm
+	m2 := powerSum(z.s-2, z.q, float64(z.kmin), z.last()) / z.norm
+	return math.Max(m2-m1*m1, 0)
+}
+
+// Sample returns a variate generated from the stream. For the unbounded
+// laws, variates beyond the largest float64, which are likely only for s
+// very close to 1, are returned as +Inf.
+func (z *Zipf) Sample(g *RngStream) float64 {
+	if z.table != nil {
+		u := g.RandU01()
+		return float64(z.kmin + sort.Search(len(z.table), func(i int) bool { return z.table[i] > u }))
+	}
+	for {
+		u := z.hn + g.RandU01()*(z.hx1-z.hn)
+		x := z.hIntegralInv(u)
+		if math.IsInf(x, 1) {
+			return x
+		}
+		k := math.Floor(x + 0.5)
+		if k < float64(z.kmin) {
+			k = float64(z.kmin)
+		} else if z.n > 0 && k > float64(z.n) {
+			k = float64(z.n)
+		}
+		if k-x <= z.sq || u >= z.hIntegral(k+0.5)-z.h(k) {
+			return k
+		}
+	}
+}
+
+// ZipfIntCap is the largest value returned by SampleInt.
+const ZipfIntCap = math.MaxInt >> 1
+
+// SampleInt returns Sample(g) as an int, with the same calls to RandU01.
+// Variates of the unbounded laws above ZipfIntCap are returned as
+// ZipfIntCap. They have probability 1 - CDF(ZipfIntCap), which is
+// negligible for s >= 1.5 but not for s close to 1: about 0.013 for
+// s = 1.1 and 0.65 for s = 1.01. Use Sample for such laws.
+func (z *Zipf) SampleInt(g *RngStream) int {
+	k := z.Sample(g)
+	if k > ZipfIntCap {
+		return ZipfIntCap
+	}
+	return int(k)
+}
+
+// h is the unnormalized probability (x + q)^(-s).
+func (z *Zipf) h(x float64) float64 {
+	return math.Exp(-z.s * math.Log(x+z.q))
+}
+
+// hIntegral is an antiderivative of h, ((x+q)^(1-s) - 1)/(1-s), written to
+// stay accurate when s is close to 1.
+func (z *Zipf) hIntegral(x float64) float64 {
+	lx := math.Log(x + z.q)
+	return expm1Ratio((1-z.s)*lx) * lx
+}
+
+// hIntegralInv is the inverse of hIntegral.
+func (z *Zipf) hIntegralInv(u float64) float64 {
+	t := u * (1 - z.s)
+	if t < -1 {
+		t = -1
+	}
+	return math.Exp(log1pRatio(t)*u) - z.q
+}
+
+// powerSumTerms is the number of leading terms added directly by
+// powerSum, beyond which the Euler-Maclaurin remainder is below the
+// rounding error.
+const powerSumTerms = 64
+
+// powerSum returns the sum of (k + q)^(-e) over the integers k in
+// [k1, k2], k2 = +Inf (with e > 1) included. The first powerSumTerms
+// terms are added directly, below 2^52; the rest is the integral of the
+// terms with the Euler-Maclaurin corrections up to the fifth derivative.
+func powerSum(e, q, k1, k2 float64) float64 {
+	f := func(y float64) float64 { return math.Exp(-e * math.Log(y)) }
+	sum := 0.0
+	k := k1
+	for ; k < k1+powerSumTerms && k < 1<<52 && k <= k2; k++ {
+		sum += f(k + q)
+	}
+	if k > k2 {
+		return sum
+	}
+	// antiderivative of the terms, (y^(1-e) - 1)/(1 - e)
+	integral := func(y float64) float64 {
+		ly := math.Log(y)
+		return expm1Ratio((1-e)*ly) * ly
+	}
+	// B_2/2! f(1)(y) + B_4/4! f(3)(y) + B_6/6! f(5)(y), f(i) the i-th
+	// derivative of the terms
+	corr := func(y float64) float64 {
+		d1 := -e * f(y) / y
+		d3 := d1 * (e + 1) * (e + 2) / (y * y)
+		d5 := d3 * (e + 3) * (e + 4) / (y * y)
+		return d1/12 - d3/720 + d5/30240
+	}
+	a := k + q
+	sum += f(a)/2 - corr(a) - integral(a)
+	if math.IsInf(k2, 1) {
+		return sum + 1/(e-1)
+	}
+	b := k2 + q
+	return sum + integral(b) + f(b)/2 + corr(b)
+}
+
+// expm1Ratio returns expm1(x)/x, with value 1 at 0.
+func expm1Ratio(x float64) float64 {
+	if math.Abs(x) > 1e-8 {
+		return math.Expm1(x) / x
+	}
+	return 1 + x/2*(1+x/3)
+}
+
+// log1pRatio returns log1p(x)/x, with value 1 at 0.
+func log1pRatio(x float64) float64 {
+	if math.Abs(x) > 1e-8 {
+		return math.Log1p(x) / x
+	}
+	return 1 - x*(0.5-x/3)
+}
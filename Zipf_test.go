@@ -0,0 +1,171 @@
+package rngstream
+
+import (
+	"math"
+	"testing"
+)
+
+// checkZipf compares the empirical frequencies of k = kmin, ..., kmin+len(p)-1
+// with the probabilities p.
+func checkZipf(t *testing.T, name string, z *Zipf, g *RngStream, kmin int, p []float64) {
+	const n = 100000
+	counts := make([]float64, len(p))
+	for i := 0; i < n; i++ {
+		k := z.SampleInt(g)
+		if k < kmin || (z.n > 0 && k > z.n) {
+			t.Fatalf("%s: sample %d out of range", name, k)
+		}
+		if k-kmin < len(p) {
+			counts[k-kmin]++
+		}
+	}
+	for i, pi := range p {
+		sd := math.Sqrt(pi * (1 - pi) / n)
+		if math.Abs(counts[i]/n-pi) > 5*sd {
+			t.Errorf("%s: frequency of %d is %v, wanted %v", name, kmin+i, counts[i]/n, pi)
+		}
+	}
+}
+
+func TestZipf(t *testing.T) {
+	SetPackageSeed([]uint64{12345, 12345, 12345, 12345, 12345, 12345})
+	g := New("zipf")
+
+	for _, c := range []struct {
+		s, q float64
+		n    int
+	}{{1, 0, 10}, {0.6, 0, 50}, {2.5, 0, 5}, {1.1, 2.5, 20}} {
+		z, err := NewZipfMandelbrot(c.s, c.q, c.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := make([]float64, c.n)
+		sum := 0.0
+		for k := 1; k <= c.n; k++ {
+			p[k-1] = math.Pow(float64(k)+c.q, -c.s)
+			sum += p[k-1]
+		}
+		for i := range p {
+			p[i] /= sum
+		}
+		checkZipf(t, "rejection-inversion", z, g, 1, p)
+		if !z.SetTableInversion(true) {
+			t.Fatalf("table inversion refused for n = %d", c.n)
+		}
+		checkZipf(t, "table", z, g, 1, p)
+	}
+
+	// unbounded: P(k) = k^-2 / zeta(2)
+	z, err := NewZipf(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zeta2 := math.Pi * math.Pi / 6
+	checkZipf(t, "unbounded", z, g, 1, []float64{1 / zeta2, 0.25 / zeta2, 1 / 9.0 / zeta2})
+	if z.SetTableInversion(true) {
+		t.Errorf("table inversion accepted for an unbounded law")
+	}
+
+	// discrete power law above kmin = 3 with alpha = 3
+	pl, err := NewDiscretePowerLaw(3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	norm := 0.0
+	for k := 3; k < 1000000; k++ {
+		norm += math.Pow(float64(k), -3)
+	}
+	checkZipf(t, "power law", pl, g, 3, []float64{1 / 27.0 / norm, 1 / 64.0 / norm})
+
+	// table inversion uses one uniform per variate
+	zt, _ := NewZipf(1.2, 100)
+	zt.SetTableInversion(true)
+	g.ResetStartSubstream()
+	k1 := zt.SampleInt(g)
+	u2 := g.RandU01()
+	g.ResetStartSubstream()
+	zt.Sample(g)
+	if g.RandU01() != u2 || k1 < 1 {
+		t.Errorf("table inversion does not use exactly one uniform")
+	}
+	// and agrees with the quantile function, within the support
+	for _, u := range []float64{0, 0.3, 0.99, 1} {
+		k := zt.Quantile(u)
+		zt.SetTableInversion(false)
+		want := zt.Quantile(u)
+		zt.SetTableInversion(true)
+		if k != want {
+			t.Errorf("table inversion: Quantile(%v) = %v, wanted %v", u, k, want)
+		}
+	}
+	if k := zt.Quantile(1); k != 100 {
+		t.Errorf("table inversion: Quantile(1) = %v, wanted 100", k)
+	}
+
+	// distribution function, quantiles and moments against direct sums,
+	// beyond the terms that are summed directly
+	for _, c := range []struct {
+		s, q    float64
+		kmin, n int
+	}{{0.6, 0, 1, 500}, {1.1, 2.5, 1, 300}, {4.5, 0, 3, 0}} {
+		z, err := newZipf(c.s, c.q, c.kmin, c.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := c.n
+		if last == 0 {
+			last = 1000000
+		}
+		var norm, m1, m2 float64
+		for k := c.kmin; k <= last; k++ {
+			w := math.Pow(float64(k)+c.q, -c.s)
+			norm += w
+			m1 += w * float64(k)
+			m2 += w * float64(k) * float64(k)
+		}
+		cum := 0.0
+		for k := c.kmin; k <= c.kmin+200; k++ {
+			p := math.Pow(float64(k)+c.q, -c.s) / norm
+			if math.Abs(z.PMF(k)-p) > 1e-9*p {
+				t.Errorf("s = %v: PMF(%d) = %v, wanted %v", c.s, k, z.PMF(k), p)
+			}
+			cum += p
+			if math.Abs(z.CDF(float64(k)+0.5)-cum) > 1e-9 {
+				t.Errorf("s = %v: CDF(%d) = %v, wanted %v", c.s, k, z.CDF(float64(k)+0.5), cum)
+			}
+		}
+		for _, u := range []float64{0.01, 0.5, 0.9, 0.999} {
+			k := z.Quantile(u)
+			if z.CDF(k) <= u || (k > float64(c.kmin) && z.CDF(k-1) > u) {
+				t.Errorf("s = %v: Quantile(%v) = %v is not the smallest k with CDF(k) > u", c.s, u, k)
+			}
+		}
+		m1, m2 = m1/norm, m2/norm
+		if math.Abs(z.Mean()-m1) > 1e-6*m1 || math.Abs(z.Variance()-(m2-m1*m1)) > 1e-4*(m2-m1*m1) {
+			t.Errorf("s = %v: mean %v and variance %v, wanted %v and %v", c.s, z.Mean(), z.Variance(), m1, m2-m1*m1)
+		}
+	}
+
+	// Sample and SampleInt draw the same variates; the integer variates
+	// of laws with s close to 1 are capped
+	heavy, _ := NewZipf(1.0001, 0)
+	g.ResetStartSubstream()
+	c := *g
+	capped := 0
+	for i := 0; i < 1000; i++ {
+		x, k := heavy.Sample(g), heavy.SampleInt(&c)
+		if x < 1 || (x <= ZipfIntCap && float64(k) != x) || (x > ZipfIntCap && k != ZipfIntCap) {
+			t.Fatalf("Sample = %v, SampleInt = %d", x, k)
+		}
+		if k == ZipfIntCap {
+			capped++
+		}
+	}
+	if p := 1 - heavy.CDF(ZipfIntCap); math.Abs(float64(capped)/1000-p) > 4*math.Sqrt(p*(1-p)/1000) {
+		t.Errorf("fraction capped %v, wanted %v", float64(capped)/1000, p)
+	}
+
+	if _, err := NewZipf(1, 0); err == nil {
+		t.Errorf("expected error for unbounded law with s = 1")
+	}
+}